@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// loadABI reads a contract ABI JSON file. Both a bare ABI array and a
+// compiler artifact with an "abi" property are accepted.
+func loadABI(path string) (abi.ABI, error) {
+	data, err := readABIJSON(path)
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	return abi.JSON(bytes.NewReader(data))
+}
+
+// abiEntry is one entry of an ABI JSON array as written, keeping the
+// internalType and component names geth does not hold on to.
+type abiEntry struct {
+	Type            string                   `json:"type"`
+	Name            string                   `json:"name"`
+	Inputs          []abi.ArgumentMarshaling `json:"inputs"`
+	Outputs         []abi.ArgumentMarshaling `json:"outputs"`
+	StateMutability string                   `json:"stateMutability"`
+	Anonymous       bool                     `json:"anonymous"`
+}
+
+// loadABIEntries reads the entries of a contract ABI JSON file without
+// interpreting them.
+func loadABIEntries(path string) ([]abiEntry, error) {
+	data, err := readABIJSON(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []abiEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// readABIJSON returns the ABI array of a file holding either the bare array
+// or a compiler artifact.
+func readABIJSON(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &artifact); err != nil {
+			return nil, err
+		}
+		data = artifact.ABI
+	}
+	return data, nil
+}
+
+// mustLoadABI loads the --abi file or exits.
+func mustLoadABI(path string) abi.ABI {
+	if path == "" {
+		fatalf("Error: No ABI file provided, use --abi")
+	}
+	contract, err := loadABI(path)
+	if err != nil {
+		fatalf("Error loading ABI %s: %v", path, err)
+	}
+	return contract
+}
+
+// loadBytecode reads the creation bytecode of a compiler artifact, either a
+// plain "bytecode" string (Hardhat, Truffle) or a {"object": ...} object
+// (Foundry).
+func loadBytecode(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var artifact struct {
+		Bytecode json.RawMessage `json:"bytecode"`
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return "", fmt.Errorf("not a compiler artifact, use --bytecode")
+	}
+	if err := json.Unmarshal(trimmed, &artifact); err != nil {
+		return "", err
+	}
+	if len(artifact.Bytecode) == 0 {
+		return "", fmt.Errorf("artifact has no bytecode, use --bytecode")
+	}
+
+	var code string
+	if err := json.Unmarshal(artifact.Bytecode, &code); err == nil {
+		return code, nil
+	}
+	var object struct {
+		Object string `json:"object"`
+	}
+	if err := json.Unmarshal(artifact.Bytecode, &object); err != nil {
+		return "", fmt.Errorf("unsupported bytecode property: %v", err)
+	}
+	return object.Object, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// callDocument is the JSON input of the call mode, for example:
+//
+//	{"signature": "transfer(address to, uint256 amount)",
+//	 "values": ["0x000000000000000000000000000000000000dEaD", 1000]}
+type callDocument struct {
+	Signature string        `json:"signature"`
+	Values    []interface{} `json:"values"`
+}
+
+// runCall prints the full calldata of a function call: the 4-byte selector
+// of the canonical signature followed by the ABI-encoded arguments.
+func runCall(data []byte, explain bool) {
+	var doc callDocument
+	if err := unmarshalJSON(data, &doc); err != nil {
+		fatalf("Error parsing JSON document: %v", err)
+	}
+
+	sig, err := parseSignature(doc.Signature)
+	if err != nil {
+		fatalf("Error parsing signature: %v", err)
+	}
+
+	if explain {
+		method, err := newMethod(sig)
+		if err != nil {
+			fatalf("Encoding error: %v", err)
+		}
+		goVals, err := goValues(method.Inputs, doc.Values)
+		if err != nil {
+			fatalf("Encoding error: %v", err)
+		}
+		printExplained(method.Inputs, goVals, method.ID)
+		return
+	}
+
+	calldata, err := encodeCall(sig, doc.Values)
+	if err != nil {
+		fatalf("Encoding error: %v", err)
+	}
+
+	fmt.Printf("0x%x\n", calldata)
+}
+
+// newMethod builds a geth method from a parsed signature, which derives the
+// canonical signature and selector.
+func newMethod(sig signature) (abi.Method, error) {
+	inputs, err := newArguments(sig.Inputs)
+	if err != nil {
+		return abi.Method{}, err
+	}
+	outputs, err := newArguments(sig.Outputs)
+	if err != nil {
+		return abi.Method{}, err
+	}
+	return abi.NewMethod(sig.Name, sig.Name, abi.Function, "nonpayable", false, false, inputs, outputs), nil
+}
+
+// encodeCall returns the selector followed by the encoded arguments.
+func encodeCall(sig signature, values []interface{}) ([]byte, error) {
+	method, err := newMethod(sig)
+	if err != nil {
+		return nil, err
+	}
+	goVals, err := goValues(method.Inputs, values)
+	if err != nil {
+		return nil, err
+	}
+	packed, err := method.Inputs.Pack(goVals...)
+	if err != nil {
+		return nil, err
+	}
+	return append(method.ID, packed...), nil
+}
+
+// runMethodCall prints the calldata of a call to a method of a contract ABI.
+// args is a JSON array of values in declaration order, or an object keyed by
+// parameter name.
+func runMethodCall(abiPath, name, argsJSON string, explain bool) {
+	contract := mustLoadABI(abiPath)
+	if name == "" {
+		fatalf("Error: No method provided, use --method")
+	}
+
+	var raw interface{}
+	if argsJSON == "" {
+		raw = []interface{}{}
+	} else if err := unmarshalJSON([]byte(argsJSON), &raw); err != nil {
+		fatalf("Error parsing --args: %v", err)
+	}
+
+	method, goVals, err := resolveMethod(contract, name, raw)
+	if err != nil {
+		fatalf("Error resolving method: %v", err)
+	}
+
+	if explain {
+		printExplained(method.Inputs, goVals, method.ID)
+		return
+	}
+
+	packed, err := method.Inputs.Pack(goVals...)
+	if err != nil {
+		fatalf("Encoding error: %v", err)
+	}
+
+	fmt.Printf("0x%x\n", append(method.ID, packed...))
+}
+
+// resolveMethod finds the method to call and converts its arguments. name may
+// be a full signature such as "attest((bytes32,(address,uint64,bool,bytes32,bytes,uint256)))",
+// geth's disambiguated name for an overload (foo0, foo1, ...), or a plain
+// Solidity name, in which case the overloads are narrowed to those whose
+// parameters accept the given values.
+func resolveMethod(contract abi.ABI, name string, raw interface{}) (abi.Method, []interface{}, error) {
+	var candidates []abi.Method
+	if strings.Contains(name, "(") {
+		sig, err := parseSignature(name)
+		if err != nil {
+			return abi.Method{}, nil, err
+		}
+		want, err := newMethod(sig)
+		if err != nil {
+			return abi.Method{}, nil, err
+		}
+		for _, m := range contract.Methods {
+			if m.Sig == want.Sig {
+				candidates = append(candidates, m)
+			}
+		}
+	} else {
+		for _, m := range contract.Methods {
+			if m.RawName == name {
+				candidates = append(candidates, m)
+			}
+		}
+		if m, ok := contract.Methods[name]; ok && len(candidates) == 0 {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return abi.Method{}, nil, fmt.Errorf("no method %q in ABI", name)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Sig < candidates[j].Sig })
+
+	var (
+		matches []abi.Method
+		matched []interface{}
+		errs    []string
+	)
+	for _, m := range candidates {
+		goVals, err := methodGoValues(m, raw)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", m.Sig, err))
+			continue
+		}
+		matches = append(matches, m)
+		matched = goVals
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], matched, nil
+	case 0:
+		return abi.Method{}, nil, fmt.Errorf("arguments do not match %s", strings.Join(errs, "; "))
+	}
+	sigs := make([]string, len(matches))
+	for i, m := range matches {
+		sigs[i] = m.Sig
+	}
+	return abi.Method{}, nil, fmt.Errorf("arguments match several overloads, pass the full signature: %s", strings.Join(sigs, ", "))
+}
+
+// methodGoValues arranges the JSON arguments in declaration order and
+// converts them for the method's parameters.
+func methodGoValues(m abi.Method, raw interface{}) ([]interface{}, error) {
+	switch args := raw.(type) {
+	case []interface{}:
+		return goValues(m.Inputs, args)
+	case map[string]interface{}:
+		if len(args) != len(m.Inputs) {
+			return nil, fmt.Errorf("expected %d arguments, got %d", len(m.Inputs), len(args))
+		}
+		values := make([]interface{}, len(m.Inputs))
+		for i, input := range m.Inputs {
+			v, ok := args[input.Name]
+			if !ok {
+				return nil, fmt.Errorf("missing argument %q", input.Name)
+			}
+			values[i] = v
+		}
+		return goValues(m.Inputs, values)
+	}
+	return nil, fmt.Errorf("arguments must be a JSON array or object, got %T", raw)
+}
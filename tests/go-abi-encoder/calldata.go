@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// decodedCalldata is the JSON output of the calldata mode. Candidates counts
+// the known functions with the calldata's selector; Matches are those whose
+// parameters decode its arguments, Rejected those that do not, with geth's
+// error. More than one candidate makes the selector ambiguous, whether or
+// not the others decode.
+type decodedCalldata struct {
+	Selector   string          `json:"selector"`
+	Ambiguous  bool            `json:"ambiguous"`
+	Candidates int             `json:"candidates"`
+	Matches    []calldataMatch `json:"matches"`
+	Rejected   []calldataMatch `json:"rejected,omitempty"`
+}
+
+// calldataMatch is one candidate function. Exact is set when re-encoding the
+// decoded values reproduces the calldata byte for byte, which geth's lenient
+// decoder does not check.
+type calldataMatch struct {
+	Name      string        `json:"name"`
+	Signature string        `json:"signature"`
+	Source    string        `json:"source"`
+	Exact     bool          `json:"exact,omitempty"`
+	Values    orderedObject `json:"values,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// calldataCandidate is a function that calldata may be a call to, with the
+// declared inputs when known, for labelling tuple components.
+type calldataCandidate struct {
+	method abi.Method
+	params []abi.ArgumentMarshaling
+	source string
+}
+
+// runCalldata identifies the function a piece of calldata calls, against the
+// functions of an ABI file or, without one, the bundled selector database,
+// and decodes its arguments.
+func runCalldata(abiPath, dataHex string) {
+	data, err := hexutil.Decode(dataHex)
+	if err != nil {
+		fatalf("Error decoding hex data: %v", err)
+	}
+	if len(data) < 4 {
+		fatalf("Error: %d bytes of calldata is too short for a selector", len(data))
+	}
+
+	var candidates []calldataCandidate
+	if abiPath != "" {
+		contract := mustLoadABI(abiPath)
+		for _, m := range contract.Methods {
+			candidates = append(candidates, calldataCandidate{m, nil, "abi"})
+		}
+	} else {
+		if candidates, err = databaseCandidates(); err != nil {
+			fatalf("Error in selector database: %v", err)
+		}
+	}
+
+	decoded := decodeCalldata(candidates, data)
+	if len(decoded.Matches) == 0 && len(decoded.Rejected) == 0 {
+		fatalf("Error: no function with selector %s", decoded.Selector)
+	}
+	printJSON(decoded)
+}
+
+// databaseCandidates builds the candidates of the bundled selector database.
+func databaseCandidates() ([]calldataCandidate, error) {
+	seen := make(map[string]bool)
+	var candidates []calldataCandidate
+	for _, s := range selectorDatabase {
+		sig, err := parseSignature(s)
+		if err != nil {
+			return nil, err
+		}
+		m, err := newMethod(sig)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", s, err)
+		}
+		if seen[m.Sig] {
+			continue
+		}
+		seen[m.Sig] = true
+		candidates = append(candidates, calldataCandidate{m, sig.Inputs, "database"})
+	}
+	return candidates, nil
+}
+
+// decodeCalldata decodes data against every candidate with a matching
+// selector.
+func decodeCalldata(candidates []calldataCandidate, data []byte) decodedCalldata {
+	out := decodedCalldata{Selector: hexutil.Encode(data[:4]), Matches: []calldataMatch{}}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].method.Sig < candidates[j].method.Sig })
+
+	for _, c := range candidates {
+		if !bytes.Equal(c.method.ID, data[:4]) {
+			continue
+		}
+		match := calldataMatch{Name: c.method.RawName, Signature: c.method.Sig, Source: c.source}
+
+		values, err := c.method.Inputs.Unpack(data[4:])
+		if err != nil {
+			match.Error = err.Error()
+			out.Rejected = append(out.Rejected, match)
+			continue
+		}
+		repacked, err := c.method.Inputs.Pack(values...)
+		match.Exact = err == nil && bytes.Equal(repacked, data[4:])
+
+		match.Values = make(orderedObject, len(values))
+		for i, input := range c.method.Inputs {
+			var components []abi.ArgumentMarshaling
+			if i < len(c.params) {
+				components = c.params[i].Components
+			}
+			match.Values[i] = field{safeName(input.Name, i), jsonValue(input.Type, components, reflect.ValueOf(values[i]))}
+		}
+		out.Matches = append(out.Matches, match)
+	}
+	out.Candidates = len(out.Matches) + len(out.Rejected)
+	out.Ambiguous = out.Candidates > 1
+	return out
+}
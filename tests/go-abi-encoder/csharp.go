@@ -0,0 +1,311 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+//go:embed AbiTestCases.cs.template
+var defaultCSharpTemplate string
+
+// csharpFile is the data the C# template is rendered with.
+type csharpFile struct {
+	Cases []csharpCase
+}
+
+// csharpCase is one AbiTestCase record, with every field already rendered
+// as C# source.
+type csharpCase struct {
+	Number    int
+	Name      string
+	Signature string
+	Values    string
+	Lines     []string
+	Hex       []string
+}
+
+// runCSharp renders a C# source file of AbiTestCase records from a corpus,
+// as printed by the fuzz and widths modes, or from a bare array of cases.
+// templatePath overrides the built-in template.
+func runCSharp(data []byte, templatePath string) {
+	var c corpus
+	if err := unmarshalJSON(data, &c); err != nil {
+		if err := unmarshalJSON(data, &c.Cases); err != nil {
+			fatalf("Error parsing corpus: %v", err)
+		}
+	}
+
+	text := defaultCSharpTemplate
+	if templatePath != "" {
+		b, err := os.ReadFile(templatePath)
+		if err != nil {
+			fatalf("Error reading template %s: %v", templatePath, err)
+		}
+		text = string(b)
+	}
+	tmpl, err := template.New("cs").Parse(text)
+	if err != nil {
+		fatalf("Error parsing template: %v", err)
+	}
+
+	var file csharpFile
+	for i, cc := range c.Cases {
+		cs, err := newCSharpCase(i+1, cc)
+		if err != nil {
+			fatalf("Error rendering case %d (%s): %v", i+1, cc.Name, err)
+		}
+		file.Cases = append(file.Cases, cs)
+	}
+
+	if err := tmpl.Execute(os.Stdout, file); err != nil {
+		fatalf("Error rendering template: %v", err)
+	}
+}
+
+// newCSharpCase encodes a corpus case with geth and renders it as C#. The
+// expected lines are the words of the encoding, annotated as by --explain.
+func newCSharpCase(n int, cc corpusCase) (csharpCase, error) {
+	args, err := newArguments(cc.Types)
+	if err != nil {
+		return csharpCase{}, err
+	}
+	goVals, err := goValues(args, cc.Values)
+	if err != nil {
+		return csharpCase{}, err
+	}
+	words, err := explainEncoding(args, goVals)
+	if err != nil {
+		return csharpCase{}, err
+	}
+
+	name := cc.Name
+	if name == "" {
+		name = fmt.Sprintf("Case %d", n)
+	}
+	sig := cc.Signature
+	if sig == "" {
+		sig = fmt.Sprintf("foo(%s)", formatParams(cc.Types))
+	}
+
+	keys := make([]string, len(args))
+	values := make([]string, len(args))
+	for i, param := range cc.Types {
+		keys[i] = safeName(param.Name, i)
+		if values[i], err = csharpValue(args[i].Type, param.Components, reflect.ValueOf(goVals[i])); err != nil {
+			return csharpCase{}, fmt.Errorf("%s: %v", keys[i], err)
+		}
+	}
+
+	cs := csharpCase{
+		Number:    n,
+		Name:      csharpString(name),
+		Signature: csharpString("function " + sig),
+		Values:    csharpKeyValues(keys, values),
+	}
+	for _, w := range words {
+		cs.Lines = append(cs.Lines, csharpString(fmt.Sprintf("0x%x  // %s %s", w.Word, w.Role, w.Path)))
+	}
+	for i, w := range words {
+		prefix := ""
+		if i == 0 {
+			prefix = "0x"
+		}
+		cs.Hex = append(cs.Hex, csharpString(fmt.Sprintf("%s%x", prefix, w.Word)))
+	}
+	if len(cs.Hex) == 0 {
+		cs.Hex = []string{csharpString("0x")}
+	}
+	return cs, nil
+}
+
+// safeName is the key AbiParam.SafeName gives a parameter: its name, or its
+// position when it has none.
+func safeName(name string, position int) string {
+	if strings.TrimSpace(name) == "" {
+		return strconv.Itoa(position)
+	}
+	return name
+}
+
+// csharpKeyValues renders an AbiKeyValues.Create call, using the name/value
+// overloads for up to three entries and the tuple overload beyond that.
+func csharpKeyValues(keys, values []string) string {
+	parts := make([]string, len(keys))
+	for i := range keys {
+		if len(keys) <= 3 {
+			parts[i] = csharpString(keys[i]) + ", " + values[i]
+		} else {
+			parts[i] = "(" + csharpString(keys[i]) + ", " + values[i] + ")"
+		}
+	}
+	return "AbiKeyValues.Create(" + strings.Join(parts, ", ") + ")"
+}
+
+// csharpType is the CLR type the C# library uses by default for t.
+func csharpType(t abi.Type) (string, error) {
+	switch t.T {
+	case abi.UintTy:
+		switch t.Size {
+		case 8:
+			return "byte", nil
+		case 16:
+			return "ushort", nil
+		case 32:
+			return "uint", nil
+		case 64:
+			return "ulong", nil
+		}
+		return "BigInteger", nil
+	case abi.IntTy:
+		switch t.Size {
+		case 8:
+			return "sbyte", nil
+		case 16:
+			return "short", nil
+		case 32:
+			return "int", nil
+		case 64:
+			return "long", nil
+		}
+		return "BigInteger", nil
+	case abi.BoolTy:
+		return "bool", nil
+	case abi.StringTy:
+		return "string", nil
+	case abi.AddressTy:
+		return "EthereumAddress", nil
+	case abi.BytesTy, abi.FixedBytesTy:
+		return "byte[]", nil
+	case abi.SliceTy, abi.ArrayTy:
+		elem, err := csharpType(*t.Elem)
+		if err != nil {
+			return "", err
+		}
+		return elem + "[]", nil
+	case abi.TupleTy:
+		return "AbiKeyValues", nil
+	}
+	return "", fmt.Errorf("no C# type for %s", t.String())
+}
+
+// csharpValue renders a packed Go value as a C# expression of the type
+// csharpType gives. components are the declared tuple components of t (or
+// of its element type), whose names become the AbiKeyValues keys.
+func csharpValue(t abi.Type, components []abi.ArgumentMarshaling, v reflect.Value) (string, error) {
+	switch t.T {
+	case abi.UintTy, abi.IntTy:
+		n := bigIntOf(v)
+		switch typ, _ := csharpType(t); typ {
+		case "byte", "ushort", "sbyte", "short":
+			return fmt.Sprintf("(%s)%s", typ, n), nil
+		case "uint":
+			return n.String() + "u", nil
+		case "int":
+			return n.String(), nil
+		case "ulong":
+			return n.String() + "ul", nil
+		case "long":
+			return n.String() + "L", nil
+		}
+		if n.IsInt64() {
+			return fmt.Sprintf("new BigInteger(%s)", n), nil
+		}
+		return fmt.Sprintf("BigInteger.Parse(\"%s\")", n), nil
+
+	case abi.BoolTy:
+		return strconv.FormatBool(v.Bool()), nil
+
+	case abi.StringTy:
+		if !utf8.ValidString(v.String()) {
+			return "", fmt.Errorf("string is not valid UTF-8")
+		}
+		return csharpString(v.String()), nil
+
+	case abi.AddressTy:
+		return fmt.Sprintf("EthereumAddress.Parse(%s)", csharpString(v.Interface().(common.Address).Hex())), nil
+
+	case abi.BytesTy, abi.FixedBytesTy:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		items := make([]string, len(b))
+		for i, c := range b {
+			items[i] = fmt.Sprintf("0x%02x", c)
+		}
+		return csharpArray("byte[]", items), nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		typ, err := csharpType(t)
+		if err != nil {
+			return "", err
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			if items[i], err = csharpValue(*t.Elem, components, v.Index(i)); err != nil {
+				return "", fmt.Errorf("[%d]: %v", i, err)
+			}
+		}
+		return csharpArray(typ, items), nil
+
+	case abi.TupleTy:
+		keys := make([]string, len(t.TupleElems))
+		values := make([]string, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			var sub []abi.ArgumentMarshaling
+			name := ""
+			if i < len(components) {
+				sub, name = components[i].Components, components[i].Name
+			}
+			keys[i] = safeName(name, i)
+			var err error
+			if values[i], err = csharpValue(*elem, sub, v.Field(i)); err != nil {
+				return "", fmt.Errorf("%s: %v", keys[i], err)
+			}
+		}
+		return csharpKeyValues(keys, values), nil
+	}
+	return "", fmt.Errorf("no C# literal for %s", t.String())
+}
+
+func csharpArray(typ string, items []string) string {
+	if len(items) == 0 {
+		return "new " + typ + " { }"
+	}
+	return "new " + typ + " { " + strings.Join(items, ", ") + " }"
+}
+
+// csharpString renders s as a C# string literal.
+func csharpString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// decodeDocument is the JSON input of the decode mode, for example:
+//
+//	{"types": "uint256 orderNumber, (bool isLatte, bool hasMilk)[] coffeeOrders",
+//	 "data": "0x000000000000000000000000000000000000000000000000000000000000002a..."}
+type decodeDocument struct {
+	Types typeList `json:"types"`
+	Data  string   `json:"data"`
+}
+
+// runDecode unpacks the hex payload of a decodeDocument with geth and prints
+// the values as a JSON array, in the form the encode mode accepts, or with
+// safeNames, keyed the way the C# AbiDecoder keys them.
+func runDecode(data []byte, safeNames bool) {
+	var doc decodeDocument
+	if err := unmarshalJSON(data, &doc); err != nil {
+		fatalf("Error parsing JSON document: %v", err)
+	}
+
+	payload, err := hexutil.Decode(doc.Data)
+	if err != nil {
+		fatalf("Error decoding hex data: %v", err)
+	}
+
+	if safeNames {
+		values, err := decodeSafeNames(doc.Types, payload)
+		if err != nil {
+			fatalf("Decoding error: %v", err)
+		}
+		printJSON(values)
+		return
+	}
+
+	values, err := decodeValues(doc.Types, payload)
+	if err != nil {
+		fatalf("Decoding error: %v", err)
+	}
+
+	printJSON(values)
+}
+
+// decodeValues unpacks an ABI payload against parameter declarations and
+// returns the values as JSON values.
+func decodeValues(params []abi.ArgumentMarshaling, payload []byte) ([]interface{}, error) {
+	args, err := newArguments(params)
+	if err != nil {
+		return nil, err
+	}
+	values, err := args.Unpack(payload)
+	if err != nil {
+		return nil, err
+	}
+	return jsonValues(params, args, values), nil
+}
+
+// decodeSafeNames unpacks an ABI payload like decodeValues, but returns the
+// shape of AbiDecodingResult.Parameters.ToDictionary: an object keyed by
+// SafeName, with every tuple, including those in arrays, an object keyed the
+// same way. Duplicate keys fail as they do in C#, except where the decoder
+// makes them unique.
+func decodeSafeNames(params []abi.ArgumentMarshaling, payload []byte) (orderedObject, error) {
+	args, err := newArguments(params)
+	if err != nil {
+		return nil, err
+	}
+	values, err := args.Unpack(payload)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := safeNameKeys(params, false, "the parameters")
+	if err != nil {
+		return nil, err
+	}
+	obj := make(orderedObject, len(values))
+	for i, v := range values {
+		value, err := safeNameValue(args[i].Type, params[i].Components, reflect.ValueOf(v), keys[i], false)
+		if err != nil {
+			return nil, err
+		}
+		obj[i] = field{keys[i], value}
+	}
+	return obj, nil
+}
+
+// safeNameValue converts a value for decodeSafeNames. unique is set for the
+// tuple elements of an array decoded by the C# DecodeDynamicArray, that is
+// one that is itself dynamic, as only its checkTypeAndSet deduplicates keys;
+// the tuples of static arrays, nested tuples and the parameters themselves
+// are filled with Dictionary.Add.
+func safeNameValue(t abi.Type, components []abi.ArgumentMarshaling, v reflect.Value, path string, unique bool) (interface{}, error) {
+	switch t.T {
+	case abi.SliceTy, abi.ArrayTy:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := safeNameValue(*t.Elem, components, v.Index(i), fmt.Sprintf("%s[%d]", path, i), isDynamic(t))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case abi.TupleTy:
+		keys, err := safeNameKeys(components, unique, path)
+		if err != nil {
+			return nil, err
+		}
+		obj := make(orderedObject, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			value, err := safeNameValue(*elem, components[i].Components, v.Field(i), path+"."+keys[i], false)
+			if err != nil {
+				return nil, err
+			}
+			obj[i] = field{keys[i], value}
+		}
+		return obj, nil
+	}
+	return jsonValue(t, components, v), nil
+}
+
+// safeNameKeys returns the dictionary keys of a parameter list: the SafeName,
+// that is the name or else the position. With unique, a clashing key is made
+// unique by appending _1, _2 and so on, as checkTypeAndSet has done since
+// 3.2.2; otherwise it is the ArgumentException Dictionary.Add throws.
+func safeNameKeys(params []abi.ArgumentMarshaling, unique bool, path string) ([]string, error) {
+	keys := make([]string, len(params))
+	used := map[string]bool{}
+	for i, p := range params {
+		key := safeName(p.Name, i)
+		if used[key] && !unique {
+			return nil, fmt.Errorf("%s: An item with the same key has already been added. Key: %s", path, key)
+		}
+		for n := 1; used[key]; n++ {
+			key = fmt.Sprintf("%s_%d", safeName(p.Name, i), n)
+		}
+		used[key] = true
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v interface{}) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fatalf("Error writing JSON: %v", err)
+	}
+	fmt.Println(string(out))
+}
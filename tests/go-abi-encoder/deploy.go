@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// runDeploy prints the init code of a contract deployment: the creation
+// bytecode followed by the ABI-encoded constructor arguments, as geth's
+// abi.Pack("") produces them. bytecode is hex; when empty, the bytecode of
+// the --abi compiler artifact is used.
+func runDeploy(abiPath, bytecode, argsJSON string) {
+	contract := mustLoadABI(abiPath)
+	if bytecode == "" {
+		var err error
+		if bytecode, err = loadBytecode(abiPath); err != nil {
+			fatalf("Error loading bytecode from %s: %v", abiPath, err)
+		}
+	}
+	code, err := decodeBytecode(bytecode)
+	if err != nil {
+		fatalf("Error decoding bytecode: %v", err)
+	}
+
+	var raw interface{}
+	if argsJSON == "" {
+		raw = []interface{}{}
+	} else if err := unmarshalJSON([]byte(argsJSON), &raw); err != nil {
+		fatalf("Error parsing --args: %v", err)
+	}
+
+	goVals, err := methodGoValues(contract.Constructor, raw)
+	if err != nil {
+		fatalf("Error converting constructor arguments: %v", err)
+	}
+	packed, err := contract.Pack("", goVals...)
+	if err != nil {
+		fatalf("Encoding error: %v", err)
+	}
+
+	fmt.Printf("0x%x\n", append(code, packed...))
+}
+
+// decodeBytecode decodes creation bytecode hex. Bytecode that still holds
+// library placeholders (__$...$__) has to be linked first.
+func decodeBytecode(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	if strings.Contains(s, "__") {
+		return nil, fmt.Errorf("bytecode contains unlinked library placeholders")
+	}
+	return hex.DecodeString(s)
+}
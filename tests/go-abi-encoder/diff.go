@@ -0,0 +1,400 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// abiChange is one difference between two versions of an ABI. Old and New
+// are the signatures of the entry in each version, Parameter the path of the
+// parameter the change is about, if any.
+type abiChange struct {
+	Kind      string `json:"kind"`
+	Code      string `json:"code"`
+	Breaking  bool   `json:"breaking"`
+	Old       string `json:"old,omitempty"`
+	New       string `json:"new,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Message   string `json:"message"`
+}
+
+// abiDiff is the JSON output of the diff mode.
+type abiDiff struct {
+	Breaking bool        `json:"breaking"`
+	Changes  []abiChange `json:"changes"`
+}
+
+// diffEntry is a function, event, error, constructor, fallback or receive
+// entry reduced to what the comparison needs.
+type diffEntry struct {
+	Name            string
+	Signature       string
+	ID              string
+	Inputs          abi.Arguments
+	Outputs         abi.Arguments
+	StateMutability string
+	Anonymous       bool
+}
+
+var diffKinds = []string{"constructor", "function", "event", "error", "fallback", "receive"}
+
+// runDiff compares an ABI file with a newer version of it and prints the
+// changes, exiting with status 1 when any of them is breaking.
+func runDiff(oldPath, newPath string) {
+	if newPath == "" {
+		fatalf("Error: No new ABI file provided, use --new")
+	}
+	oldABI := mustLoadABI(oldPath)
+	newABI := mustLoadABI(newPath)
+
+	diff := diffABIs(oldABI, newABI)
+	printJSON(diff)
+	if diff.Breaking {
+		os.Exit(1)
+	}
+}
+
+// diffABIs pairs the entries of two ABIs by canonical signature. Entries
+// left over on both sides under the same name are taken to be one entry whose
+// parameter types changed; any others were removed or added.
+func diffABIs(oldABI, newABI abi.ABI) abiDiff {
+	d := abiDiff{Changes: []abiChange{}}
+	oldEntries, newEntries := diffEntries(oldABI), diffEntries(newABI)
+
+	for _, kind := range diffKinds {
+		olds, news := oldEntries[kind], newEntries[kind]
+		removed := map[string][]diffEntry{}
+		added := map[string][]diffEntry{}
+		names := map[string]bool{}
+
+		for _, key := range sortedKeys(olds) {
+			if n, ok := news[key]; ok {
+				d.compare(kind, olds[key], n)
+				continue
+			}
+			removed[olds[key].Name] = append(removed[olds[key].Name], olds[key])
+			names[olds[key].Name] = true
+		}
+		for _, key := range sortedKeys(news) {
+			if _, ok := olds[key]; !ok {
+				added[news[key].Name] = append(added[news[key].Name], news[key])
+				names[news[key].Name] = true
+			}
+		}
+
+		for _, name := range sortedKeys(names) {
+			o, n := removed[name], added[name]
+			if len(o) == 1 && len(n) == 1 {
+				d.changedSignature(kind, o[0], n[0])
+				continue
+			}
+			for _, e := range o {
+				d.add(abiChange{
+					Kind:     kind,
+					Code:     "removed",
+					Breaking: kind != "error",
+					Old:      e.Signature,
+					Message:  fmt.Sprintf("%s is removed", describeEntry(kind, e.Signature)),
+				})
+			}
+			for _, e := range n {
+				d.add(abiChange{
+					Kind:    kind,
+					Code:    "added",
+					New:     e.Signature,
+					Message: fmt.Sprintf("%s is added", describeEntry(kind, e.Signature)),
+				})
+			}
+		}
+	}
+	return d
+}
+
+// diffEntries groups the entries of an ABI by kind, keyed by canonical
+// signature. The constructor is keyed by its kind alone, so that a change of
+// its parameters is not mistaken for a removal, and an ABI without one has
+// the implicit constructor().
+func diffEntries(contract abi.ABI) map[string]map[string]diffEntry {
+	entries := map[string]map[string]diffEntry{}
+	for _, kind := range diffKinds {
+		entries[kind] = map[string]diffEntry{}
+	}
+
+	constructor := diffEntry{
+		Name:            "constructor",
+		Signature:       "constructor(" + argumentTypes(contract.Constructor.Inputs) + ")",
+		Inputs:          contract.Constructor.Inputs,
+		StateMutability: contract.Constructor.StateMutability,
+	}
+	if constructor.StateMutability == "" {
+		constructor.StateMutability = "nonpayable"
+	}
+	entries["constructor"]["constructor"] = constructor
+
+	for _, m := range contract.Methods {
+		entries["function"][m.Sig] = diffEntry{
+			Name:            m.RawName,
+			Signature:       m.Sig,
+			ID:              hexutil.Encode(m.ID),
+			Inputs:          m.Inputs,
+			Outputs:         m.Outputs,
+			StateMutability: m.StateMutability,
+		}
+	}
+	for _, e := range contract.Events {
+		entries["event"][e.Sig] = diffEntry{
+			Name:      e.RawName,
+			Signature: e.Sig,
+			ID:        e.ID.Hex(),
+			Inputs:    e.Inputs,
+			Anonymous: e.Anonymous,
+		}
+	}
+	for _, e := range contract.Errors {
+		entries["error"][e.Sig] = diffEntry{
+			Name:      e.Name,
+			Signature: e.Sig,
+			ID:        hexutil.Encode(e.ID[:4]),
+			Inputs:    e.Inputs,
+		}
+	}
+	if contract.HasFallback() {
+		entries["fallback"]["fallback"] = diffEntry{Name: "fallback", Signature: "fallback()", StateMutability: contract.Fallback.StateMutability}
+	}
+	if contract.HasReceive() {
+		entries["receive"]["receive"] = diffEntry{Name: "receive", Signature: "receive()", StateMutability: contract.Receive.StateMutability}
+	}
+	return entries
+}
+
+// changedSignature reports an entry whose parameter types changed, and so
+// its selector or topic, then compares what can still be compared.
+func (d *abiDiff) changedSignature(kind string, o, n diffEntry) {
+	code, id := "selector-changed", "selector"
+	if kind == "event" {
+		code, id = "topic-changed", "topic"
+	}
+	d.add(abiChange{
+		Kind:     kind,
+		Code:     code,
+		Breaking: true,
+		Old:      o.Signature,
+		New:      n.Signature,
+		Message:  fmt.Sprintf("%s %s becomes %s, its %s changes from %s to %s", kind, o.Signature, n.Signature, id, o.ID, n.ID),
+	})
+	if kind == "function" {
+		d.compareOutputs(kind, o, n)
+		d.compareMutability(kind, o, n)
+	}
+}
+
+// compare reports the differences between two versions of an entry with the
+// same key.
+func (d *abiDiff) compare(kind string, o, n diffEntry) {
+	if kind == "constructor" && o.Signature != n.Signature {
+		d.add(abiChange{
+			Kind:     kind,
+			Code:     "constructor-changed",
+			Breaking: true,
+			Old:      o.Signature,
+			New:      n.Signature,
+			Message:  fmt.Sprintf("%s becomes %s, deployment arguments must change", o.Signature, n.Signature),
+		})
+	} else {
+		// Inputs are encoded by position, so renaming one only matters to
+		// callers passing arguments by name. Event and error fields are
+		// decoded, and decoders such as AbiDecodingResult key them by name.
+		d.compareNames(kind, o, n, "inputs", o.Inputs, n.Inputs, kind == "event" || kind == "error")
+	}
+
+	if kind == "event" {
+		for i := range o.Inputs {
+			if o.Inputs[i].Indexed != n.Inputs[i].Indexed {
+				d.add(abiChange{
+					Kind:      kind,
+					Code:      "indexed-changed",
+					Breaking:  true,
+					Old:       o.Signature,
+					New:       n.Signature,
+					Parameter: fmt.Sprintf("inputs[%d]", i),
+					Message:   fmt.Sprintf("%s is %s, it moves between the topics and the data", describeParam("inputs", i, n.Inputs[i].Name), indexedWord(n.Inputs[i].Indexed)),
+				})
+			}
+		}
+		if o.Anonymous != n.Anonymous {
+			d.add(abiChange{
+				Kind:     kind,
+				Code:     "anonymous-changed",
+				Breaking: true,
+				Old:      o.Signature,
+				New:      n.Signature,
+				Message:  fmt.Sprintf("event %s is %s, which changes whether topic0 holds its topic hash", n.Signature, anonymousWord(n.Anonymous)),
+			})
+		}
+	}
+
+	if kind == "function" {
+		d.compareOutputs(kind, o, n)
+	}
+	d.compareMutability(kind, o, n)
+}
+
+// compareOutputs reports changes to the return values of a function, which
+// are not part of its selector and so go unnoticed by callers until decoding
+// fails or, worse, succeeds with the wrong values.
+func (d *abiDiff) compareOutputs(kind string, o, n diffEntry) {
+	if len(o.Outputs) != len(n.Outputs) {
+		d.add(abiChange{
+			Kind:     kind,
+			Code:     "outputs-changed",
+			Breaking: true,
+			Old:      o.Signature,
+			New:      n.Signature,
+			Message:  fmt.Sprintf("outputs change from (%s) to (%s)", argumentTypes(o.Outputs), argumentTypes(n.Outputs)),
+		})
+		return
+	}
+	typesChanged := false
+	for i := range o.Outputs {
+		if ot, nt := o.Outputs[i].Type.String(), n.Outputs[i].Type.String(); ot != nt {
+			typesChanged = true
+			d.add(abiChange{
+				Kind:      kind,
+				Code:      "output-type-changed",
+				Breaking:  true,
+				Old:       o.Signature,
+				New:       n.Signature,
+				Parameter: fmt.Sprintf("outputs[%d]", i),
+				Message:   fmt.Sprintf("%s changes type from %s to %s", describeParam("outputs", i, n.Outputs[i].Name), ot, nt),
+			})
+		}
+	}
+	if !typesChanged {
+		d.compareNames(kind, o, n, "outputs", o.Outputs, n.Outputs, true)
+	}
+}
+
+// compareMutability reports state mutability changes. Those that make an
+// entry less permissive are breaking: a view that now writes fails under
+// eth_call and STATICCALL, and a payable entry that no longer is reverts
+// when sent value.
+func (d *abiDiff) compareMutability(kind string, o, n diffEntry) {
+	if o.StateMutability == n.StateMutability {
+		return
+	}
+	readOnly := func(s string) bool { return s == "view" || s == "pure" }
+	breaking := (readOnly(o.StateMutability) && !readOnly(n.StateMutability)) ||
+		(o.StateMutability == "payable" && n.StateMutability != "payable")
+	d.add(abiChange{
+		Kind:     kind,
+		Code:     "state-mutability-changed",
+		Breaking: breaking,
+		Old:      o.Signature,
+		New:      n.Signature,
+		Message:  fmt.Sprintf("stateMutability changes from %s to %s", o.StateMutability, n.StateMutability),
+	})
+}
+
+// compareNames reports renamed parameters and tuple components of two
+// argument lists with the same types.
+func (d *abiDiff) compareNames(kind string, o, n diffEntry, list string, olds, news abi.Arguments, breaking bool) {
+	for i := range olds {
+		path := fmt.Sprintf("%s[%d]", list, i)
+		if olds[i].Name != news[i].Name {
+			d.renamed(kind, o, n, path, olds[i].Name, news[i].Name, breaking)
+		}
+		d.compareComponentNames(kind, o, n, path, olds[i].Type, news[i].Type, breaking)
+	}
+}
+
+// compareComponentNames walks two identical type trees for renamed tuple
+// components. Array element components share the path of the array, as in
+// the ABI JSON.
+func (d *abiDiff) compareComponentNames(kind string, o, n diffEntry, path string, ot, nt abi.Type, breaking bool) {
+	switch ot.T {
+	case abi.SliceTy, abi.ArrayTy:
+		d.compareComponentNames(kind, o, n, path, *ot.Elem, *nt.Elem, breaking)
+	case abi.TupleTy:
+		for i := range ot.TupleElems {
+			p := fmt.Sprintf("%s.components[%d]", path, i)
+			if ot.TupleRawNames[i] != nt.TupleRawNames[i] {
+				d.renamed(kind, o, n, p, ot.TupleRawNames[i], nt.TupleRawNames[i], breaking)
+			}
+			d.compareComponentNames(kind, o, n, p, *ot.TupleElems[i], *nt.TupleElems[i], breaking)
+		}
+	}
+}
+
+func (d *abiDiff) renamed(kind string, o, n diffEntry, path, oldName, newName string, breaking bool) {
+	effect := "the encoding is unchanged but arguments passed by name must use the new name"
+	if breaking {
+		effect = "the encoding is unchanged but values decoded by name are keyed differently"
+	}
+	d.add(abiChange{
+		Kind:      kind,
+		Code:      "parameter-renamed",
+		Breaking:  breaking,
+		Old:       o.Signature,
+		New:       n.Signature,
+		Parameter: path,
+		Message:   fmt.Sprintf("%s is renamed from %q to %q, %s", path, oldName, newName, effect),
+	})
+}
+
+func (d *abiDiff) add(c abiChange) {
+	d.Changes = append(d.Changes, c)
+	d.Breaking = d.Breaking || c.Breaking
+}
+
+// describeEntry names an entry for messages, without repeating the kind of
+// a constructor, fallback or receive entry.
+func describeEntry(kind, sig string) string {
+	if strings.HasPrefix(sig, kind+"(") {
+		return sig
+	}
+	return kind + " " + sig
+}
+
+// describeParam names a parameter for messages, by position when unnamed.
+func describeParam(list string, i int, name string) string {
+	if name == "" {
+		return fmt.Sprintf("%s[%d]", list, i)
+	}
+	return fmt.Sprintf("%s[%d] %q", list, i, name)
+}
+
+func argumentTypes(args abi.Arguments) string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = arg.Type.String()
+	}
+	return strings.Join(types, ",")
+}
+
+func indexedWord(indexed bool) string {
+	if indexed {
+		return "now indexed"
+	}
+	return "no longer indexed"
+}
+
+func anonymousWord(anonymous bool) string {
+	if anonymous {
+		return "now anonymous"
+	}
+	return "no longer anonymous"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// easDocument is the JSON input of the eas mode, for example:
+//
+//	{"schema": "uint256 id, string name, bool ok",
+//	 "resolver": "0x0000000000000000000000000000000000000000", "revocable": true,
+//	 "values": [1, "abc", true],
+//	 "attestation": {"recipient": "0x...", "attester": "0x...", "time": 1700000000}}
+//
+// The schema UID is always computed. values, when present, are encoded as the
+// attestation data, and attestation, when present, gives the fields of an
+// on-chain attestation whose UID is computed.
+type easDocument struct {
+	Schema      string          `json:"schema"`
+	Resolver    string          `json:"resolver"`
+	Revocable   bool            `json:"revocable"`
+	Values      []interface{}   `json:"values"`
+	Attestation *easAttestation `json:"attestation"`
+}
+
+// easAttestation holds the fields of an Attestation that its UID commits to.
+// schema defaults to the document's schema UID, revocable to the document's
+// revocable flag, data to the encoded values and bump to 0.
+type easAttestation struct {
+	Schema         string      `json:"schema"`
+	Recipient      string      `json:"recipient"`
+	Attester       string      `json:"attester"`
+	Time           interface{} `json:"time"`
+	ExpirationTime interface{} `json:"expirationTime"`
+	Revocable      *bool       `json:"revocable"`
+	RefUID         string      `json:"refUID"`
+	Data           *string     `json:"data"`
+	Bump           interface{} `json:"bump"`
+}
+
+// easResult is the JSON output of the eas mode.
+type easResult struct {
+	SchemaUID      string `json:"schemaUID"`
+	Data           string `json:"data,omitempty"`
+	AttestationUID string `json:"attestationUID,omitempty"`
+}
+
+// runEAS computes the UIDs and attestation data of an easDocument.
+func runEAS(data []byte) {
+	var doc easDocument
+	if err := unmarshalJSON(data, &doc); err != nil {
+		fatalf("Error parsing JSON document: %v", err)
+	}
+
+	result, err := easUIDs(doc)
+	if err != nil {
+		fatalf("Error: %v", err)
+	}
+	printJSON(result)
+}
+
+// easUIDs follows the EAS contracts: SchemaRegistry derives a schema UID
+// from keccak256(abi.encodePacked(schema, resolver, revocable)), and EAS an
+// attestation UID from keccak256(abi.encodePacked(schema, recipient,
+// attester, time, expirationTime, revocable, refUID, data, bump)). The
+// attestation data is the plain ABI encoding of the schema's fields.
+func easUIDs(doc easDocument) (easResult, error) {
+	resolver := doc.Resolver
+	if resolver == "" {
+		resolver = common.Address{}.Hex()
+	}
+	schemaUID, err := packedHash("string schema, address resolver, bool revocable",
+		doc.Schema, resolver, doc.Revocable)
+	if err != nil {
+		return easResult{}, fmt.Errorf("schema UID: %v", err)
+	}
+	result := easResult{SchemaUID: hexutil.Encode(schemaUID)}
+
+	if doc.Values != nil {
+		params, err := parseTypeList(doc.Schema)
+		if err != nil {
+			return easResult{}, fmt.Errorf("schema: %v", err)
+		}
+		encoded, err := encodeValues(params, doc.Values)
+		if err != nil {
+			return easResult{}, fmt.Errorf("data: %v", err)
+		}
+		result.Data = hexutil.Encode(encoded)
+	}
+
+	if a := doc.Attestation; a != nil {
+		schema := a.Schema
+		if schema == "" {
+			schema = result.SchemaUID
+		}
+		revocable := doc.Revocable
+		if a.Revocable != nil {
+			revocable = *a.Revocable
+		}
+		refUID := a.RefUID
+		if refUID == "" {
+			refUID = common.Hash{}.Hex()
+		}
+		attestationData := result.Data
+		if a.Data != nil {
+			attestationData = *a.Data
+		}
+		if attestationData == "" {
+			attestationData = "0x"
+		}
+
+		uid, err := packedHash("bytes32 schema, address recipient, address attester, uint64 time, uint64 expirationTime, bool revocable, bytes32 refUID, bytes data, uint32 bump",
+			schema, orZeroAddress(a.Recipient), orZeroAddress(a.Attester), orZero(a.Time), orZero(a.ExpirationTime),
+			revocable, refUID, attestationData, orZero(a.Bump))
+		if err != nil {
+			return easResult{}, fmt.Errorf("attestation UID: %v", err)
+		}
+		result.AttestationUID = hexutil.Encode(uid)
+	}
+	return result, nil
+}
+
+// packedHash is the keccak256 of the packed encoding of JSON values.
+func packedHash(types string, values ...interface{}) ([]byte, error) {
+	params, err := parseTypeList(types)
+	if err != nil {
+		return nil, err
+	}
+	packed, err := encodePackedValues(params, values)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(packed), nil
+}
+
+func orZero(v interface{}) interface{} {
+	if v == nil {
+		return "0"
+	}
+	return v
+}
+
+func orZeroAddress(s string) string {
+	if s == "" {
+		return common.Address{}.Hex()
+	}
+	return s
+}
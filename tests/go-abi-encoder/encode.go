@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// encodeDocument is the JSON input of the encode mode, for example:
+//
+//	{"types": "bool isActive, (string id, uint256 balance) account",
+//	 "values": [true, {"id": "abc", "balance": 9}]}
+type encodeDocument struct {
+	Types  typeList      `json:"types"`
+	Values []interface{} `json:"values"`
+}
+
+// runEncode ABI-encodes the values of an encodeDocument and prints the hex,
+// or with explain, the annotated words.
+func runEncode(data []byte, explain bool) {
+	var doc encodeDocument
+	if err := unmarshalJSON(data, &doc); err != nil {
+		fatalf("Error parsing JSON document: %v", err)
+	}
+
+	if explain {
+		args, err := newArguments(doc.Types)
+		if err != nil {
+			fatalf("Encoding error: %v", err)
+		}
+		goVals, err := goValues(args, doc.Values)
+		if err != nil {
+			fatalf("Encoding error: %v", err)
+		}
+		printExplained(args, goVals, nil)
+		return
+	}
+
+	encoded, err := encodeValues(doc.Types, doc.Values)
+	if err != nil {
+		fatalf("Encoding error: %v", err)
+	}
+
+	fmt.Printf("0x%x\n", encoded)
+}
+
+// encodeValues packs JSON values against parameter declarations.
+func encodeValues(params []abi.ArgumentMarshaling, values []interface{}) ([]byte, error) {
+	args, err := newArguments(params)
+	if err != nil {
+		return nil, err
+	}
+	goVals, err := goValues(args, values)
+	if err != nil {
+		return nil, err
+	}
+	return args.Pack(goVals...)
+}
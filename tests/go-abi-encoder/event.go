@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// eventDocument is the JSON input of the event mode, for example:
+//
+//	{"signature": "event Transfer(address indexed from, address indexed to, uint256 value)",
+//	 "values": ["0x...", "0x...", 1000]}
+//
+// Values are given for every parameter in declaration order, indexed or not.
+type eventDocument struct {
+	Signature string        `json:"signature"`
+	Values    []interface{} `json:"values"`
+}
+
+// eventLog is the JSON output of the event mode, shaped like the topics and
+// data of an eth_getLogs entry.
+type eventLog struct {
+	Signature string   `json:"signature"`
+	Topics    []string `json:"topics"`
+	Data      string   `json:"data"`
+}
+
+// runEvent prints the log an emitted event would produce.
+func runEvent(data []byte) {
+	var doc eventDocument
+	if err := unmarshalJSON(data, &doc); err != nil {
+		fatalf("Error parsing JSON document: %v", err)
+	}
+
+	sig, err := parseSignature(doc.Signature)
+	if err != nil {
+		fatalf("Error parsing signature: %v", err)
+	}
+
+	log, err := encodeEvent(sig, doc.Values)
+	if err != nil {
+		fatalf("Encoding error: %v", err)
+	}
+
+	printJSON(log)
+}
+
+// newEvent builds a geth event from a parsed signature, which derives the
+// canonical signature and topic0.
+func newEvent(sig signature) (abi.Event, error) {
+	inputs, err := newArguments(sig.Inputs)
+	if err != nil {
+		return abi.Event{}, err
+	}
+	return abi.NewEvent(sig.Name, sig.Name, sig.Anonymous, inputs), nil
+}
+
+// encodeEvent splits the values into topics and data. topic0 is the hash of
+// the canonical signature unless the event is anonymous; indexed values are
+// turned into topics by geth's MakeTopics, which hashes strings and bytes;
+// the remaining values are ABI-encoded into the data section.
+func encodeEvent(sig signature, values []interface{}) (eventLog, error) {
+	event, err := newEvent(sig)
+	if err != nil {
+		return eventLog{}, err
+	}
+	goVals, err := goValues(event.Inputs, values)
+	if err != nil {
+		return eventLog{}, err
+	}
+
+	var topics []common.Hash
+	if !event.Anonymous {
+		topics = append(topics, event.ID)
+	}
+
+	var dataVals []interface{}
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			dataVals = append(dataVals, goVals[i])
+			continue
+		}
+		switch input.Type.T {
+		case abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+			// MakeTopics does not implement the hashed in-place encoding
+			// Solidity uses for these, and would hash a uint8[] as bytes.
+			return eventLog{}, fmt.Errorf("%s: indexed %s values are not supported", input.Name, input.Type.String())
+		}
+		topic, err := abi.MakeTopics([]interface{}{goVals[i]})
+		if err != nil {
+			return eventLog{}, fmt.Errorf("%s: %v", input.Name, err)
+		}
+		topics = append(topics, topic[0][0])
+	}
+
+	if max := 4; len(topics) > max {
+		return eventLog{}, fmt.Errorf("%d topics exceed the EVM limit of %d", len(topics), max)
+	}
+
+	packed, err := event.Inputs.NonIndexed().Pack(dataVals...)
+	if err != nil {
+		return eventLog{}, err
+	}
+
+	log := eventLog{
+		Signature: event.Sig,
+		Topics:    make([]string, len(topics)),
+		Data:      hexutil.Encode(packed),
+	}
+	for i, topic := range topics {
+		log.Topics[i] = topic.Hex()
+	}
+	return log, nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// explainedWord is one 32-byte word of an encoding, with the role it plays
+// and the path of the parameter it belongs to, e.g. account.user.name[1].
+//
+// Roles are "head" (a static value in a head), "offset" (a pointer to
+// dynamic data), "length" (the length of a string, bytes or dynamic array),
+// "element" (a static array element), "data" (string or bytes content) and
+// "data+padding" (the last, zero-padded word of string or bytes content).
+type explainedWord struct {
+	Word []byte
+	Role string
+	Path string
+}
+
+// explainItem is a value to be laid out in a head/tail sequence.
+type explainItem struct {
+	t    abi.Type
+	v    reflect.Value
+	path string
+}
+
+// printExplained prints the encoding of values word by word. A selector, if
+// given, is printed first; offsets are relative to the start of the
+// arguments, as ABI offsets are.
+func printExplained(args abi.Arguments, values []interface{}, selector []byte) {
+	words, err := explainEncoding(args, values)
+	if err != nil {
+		fatalf("Explain error: %v", err)
+	}
+
+	if selector != nil {
+		fmt.Printf("%-8s   %x\n", "selector", selector)
+	}
+	for i, w := range words {
+		fmt.Printf("%#06x   %x  %-12s %s\n", i*32, w.Word, w.Role, w.Path)
+	}
+}
+
+// explainEncoding lays out the encoding of values word by word and checks the
+// layout reproduces geth's packed output.
+func explainEncoding(args abi.Arguments, values []interface{}) ([]explainedWord, error) {
+	items := make([]explainItem, len(args))
+	for i, arg := range args {
+		path := arg.Name
+		if path == "" {
+			path = fmt.Sprintf("[%d]", i)
+		}
+		items[i] = explainItem{arg.Type, reflect.ValueOf(values[i]), path}
+	}
+	words := explainSequence(items, "head")
+
+	packed, err := args.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+	var laidOut []byte
+	for _, w := range words {
+		laidOut = append(laidOut, w.Word...)
+	}
+	if !bytes.Equal(laidOut, packed) {
+		return nil, fmt.Errorf("layout does not match geth's encoding\n  geth:   0x%x\n  layout: 0x%x", packed, laidOut)
+	}
+	return words, nil
+}
+
+// explainSequence lays out items the way tuples, dynamic-element arrays and
+// argument lists are encoded: a head with static values inline and offsets to
+// dynamic values, followed by the dynamic values in order. role is the role
+// given to static values in the head.
+func explainSequence(items []explainItem, role string) []explainedWord {
+	var head, tail []explainedWord
+	headSize := 0
+	for _, item := range items {
+		if isDynamic(item.t) {
+			headSize += 32
+		} else {
+			headSize += staticSize(item.t)
+		}
+	}
+
+	for _, item := range items {
+		if !isDynamic(item.t) {
+			head = append(head, explainStatic(item, role)...)
+			continue
+		}
+		offset := headSize + 32*len(tail)
+		head = append(head, explainedWord{math.U256Bytes(big.NewInt(int64(offset))), "offset", item.path})
+		tail = append(tail, explainDynamic(item)...)
+	}
+	return append(head, tail...)
+}
+
+// explainStatic lays out a value of a static type inline.
+func explainStatic(item explainItem, role string) []explainedWord {
+	switch item.t.T {
+	case abi.ArrayTy:
+		return explainSequence(arrayItems(item), "element")
+	case abi.TupleTy:
+		return explainSequence(tupleItems(item), role)
+	}
+	word, err := abi.Arguments{{Type: item.t}}.Pack(item.v.Interface())
+	if err != nil {
+		fatalf("Encoding error at %s: %v", item.path, err)
+	}
+	return []explainedWord{{word, role, item.path}}
+}
+
+// explainDynamic lays out the tail of a dynamic value.
+func explainDynamic(item explainItem) []explainedWord {
+	switch item.t.T {
+	case abi.StringTy, abi.BytesTy:
+		var content []byte
+		if item.t.T == abi.StringTy {
+			content = []byte(item.v.String())
+		} else {
+			content = item.v.Bytes()
+		}
+		words := []explainedWord{lengthWord(len(content), item.path)}
+		for i := 0; i < len(content); i += 32 {
+			chunk := make([]byte, 32)
+			n := copy(chunk, content[i:])
+			role := "data"
+			if n < 32 {
+				role = "data+padding"
+			}
+			words = append(words, explainedWord{chunk, role, item.path})
+		}
+		return words
+
+	case abi.SliceTy:
+		words := []explainedWord{lengthWord(item.v.Len(), item.path)}
+		return append(words, explainSequence(arrayItems(item), "element")...)
+
+	case abi.ArrayTy:
+		return explainSequence(arrayItems(item), "element")
+
+	case abi.TupleTy:
+		return explainSequence(tupleItems(item), "head")
+	}
+	fatalf("Explain error: unexpected dynamic type %s at %s", item.t.String(), item.path)
+	return nil
+}
+
+func lengthWord(n int, path string) explainedWord {
+	return explainedWord{math.U256Bytes(big.NewInt(int64(n))), "length", path}
+}
+
+func arrayItems(item explainItem) []explainItem {
+	items := make([]explainItem, item.v.Len())
+	for i := range items {
+		items[i] = explainItem{*item.t.Elem, item.v.Index(i), fmt.Sprintf("%s[%d]", item.path, i)}
+	}
+	return items
+}
+
+func tupleItems(item explainItem) []explainItem {
+	items := make([]explainItem, len(item.t.TupleElems))
+	for i, elem := range item.t.TupleElems {
+		items[i] = explainItem{*elem, item.v.Field(i), item.path + "." + item.t.TupleRawNames[i]}
+	}
+	return items
+}
+
+// isDynamic reports whether t is encoded out of line, following the ABI
+// specification's definition of dynamic types.
+func isDynamic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy:
+		return true
+	case abi.ArrayTy:
+		return isDynamic(*t.Elem)
+	case abi.TupleTy:
+		for _, elem := range t.TupleElems {
+			if isDynamic(*elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// staticSize is the number of bytes a static type occupies inline.
+func staticSize(t abi.Type) int {
+	switch t.T {
+	case abi.ArrayTy:
+		return t.Size * staticSize(*t.Elem)
+	case abi.TupleTy:
+		size := 0
+		for _, elem := range t.TupleElems {
+			size += staticSize(*elem)
+		}
+		return size
+	}
+	return 32
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// corpus is a set of generated cases, together with the seed that reproduces
+// them when they are random.
+type corpus struct {
+	Seed  *int64       `json:"seed,omitempty"`
+	Cases []corpusCase `json:"cases"`
+}
+
+// corpusCase is one generated case. It is a superset of encodeDocument, so a
+// single case can be fed back to the encode mode.
+type corpusCase struct {
+	Name      string        `json:"name"`
+	Signature string        `json:"signature"`
+	Types     typeList      `json:"types"`
+	Values    []interface{} `json:"values"`
+	Encoded   string        `json:"encoded"`
+}
+
+// runFuzz prints a corpus of count random cases. The same seed always yields
+// the same corpus.
+func runFuzz(seed int64, count, depth int) {
+	g := &generator{rnd: rand.New(rand.NewSource(seed)), maxDepth: depth}
+
+	if count < 0 {
+		fatalf("Error: --count must not be negative")
+	}
+	c := corpus{Seed: &seed, Cases: make([]corpusCase, 0, count)}
+	for i := 1; i <= count; i++ {
+		fc, err := g.randomCase(i)
+		if err != nil {
+			fatalf("Error generating case %d: %v", i, err)
+		}
+		c.Cases = append(c.Cases, fc)
+	}
+
+	printJSON(c)
+}
+
+// generator produces random but valid ABI type trees and matching values.
+type generator struct {
+	rnd      *rand.Rand
+	maxDepth int
+}
+
+// randomCase generates a parameter list and values, encodes them with geth
+// and checks that geth decodes the encoding back to the same values.
+func (g *generator) randomCase(n int) (corpusCase, error) {
+	params := make([]abi.ArgumentMarshaling, 1+g.rnd.Intn(4))
+	for i := range params {
+		params[i] = g.randomParam(0)
+		params[i].Name = fmt.Sprintf("p%d", i)
+	}
+	args, err := newArguments(params)
+	if err != nil {
+		return corpusCase{}, err
+	}
+
+	generated := make([]interface{}, len(args))
+	for i, arg := range args {
+		generated[i] = g.randomValue(arg.Type)
+	}
+
+	// Round-trip through JSON so the values are exactly what a reader of the
+	// corpus will see.
+	data, err := json.Marshal(generated)
+	if err != nil {
+		return corpusCase{}, err
+	}
+	var values []interface{}
+	if err := unmarshalJSON(data, &values); err != nil {
+		return corpusCase{}, err
+	}
+
+	encoded, err := encodeValues(params, values)
+	if err != nil {
+		return corpusCase{}, err
+	}
+
+	decoded, err := decodeValues(params, encoded)
+	if err != nil {
+		return corpusCase{}, fmt.Errorf("decoding own encoding: %v", err)
+	}
+	if again, err := json.Marshal(decoded); err != nil || string(again) != string(data) {
+		return corpusCase{}, fmt.Errorf("round trip mismatch:\n  %s\n  %s", data, again)
+	}
+
+	return corpusCase{
+		Name:      fmt.Sprintf("Fuzz case %d", n),
+		Signature: fmt.Sprintf("foo(%s)", formatParams(params)),
+		Types:     params,
+		Values:    values,
+		Encoded:   hexutil.Encode(encoded),
+	}, nil
+}
+
+// randomParam returns a random type. Composite types become less likely with
+// depth and are not generated past maxDepth.
+func (g *generator) randomParam(depth int) abi.ArgumentMarshaling {
+	if depth < g.maxDepth {
+		switch g.rnd.Intn(6) {
+		case 0:
+			p := g.randomParam(depth + 1)
+			if g.rnd.Intn(2) == 0 {
+				p.Type += "[]"
+			} else {
+				p.Type += fmt.Sprintf("[%d]", 1+g.rnd.Intn(3))
+			}
+			return p
+		case 1:
+			components := make([]abi.ArgumentMarshaling, 1+g.rnd.Intn(4))
+			for i := range components {
+				components[i] = g.randomParam(depth + 1)
+				components[i].Name = fmt.Sprintf("f%d", i)
+			}
+			return abi.ArgumentMarshaling{Type: "tuple", Components: components}
+		}
+	}
+
+	switch g.rnd.Intn(7) {
+	case 0:
+		return abi.ArgumentMarshaling{Type: fmt.Sprintf("uint%d", 8*(1+g.rnd.Intn(32)))}
+	case 1:
+		return abi.ArgumentMarshaling{Type: fmt.Sprintf("int%d", 8*(1+g.rnd.Intn(32)))}
+	case 2:
+		return abi.ArgumentMarshaling{Type: "address"}
+	case 3:
+		return abi.ArgumentMarshaling{Type: "bool"}
+	case 4:
+		return abi.ArgumentMarshaling{Type: fmt.Sprintf("bytes%d", 1+g.rnd.Intn(32))}
+	case 5:
+		return abi.ArgumentMarshaling{Type: "bytes"}
+	}
+	return abi.ArgumentMarshaling{Type: "string"}
+}
+
+// randomValue returns a random JSON value for t, favouring the boundaries of
+// integer ranges and empty dynamic values.
+func (g *generator) randomValue(t abi.Type) interface{} {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		min, max := intBounds(t)
+		switch g.rnd.Intn(5) {
+		case 0:
+			return json.Number(min.String())
+		case 1:
+			return json.Number(max.String())
+		case 2:
+			return json.Number("0")
+		}
+		span := new(big.Int).Sub(max, min)
+		n := new(big.Int).Rand(g.rnd, span.Add(span, big.NewInt(1)))
+		return json.Number(n.Add(n, min).String())
+
+	case abi.BoolTy:
+		return g.rnd.Intn(2) == 1
+
+	case abi.AddressTy:
+		return common.BytesToAddress(g.randomBytes(20)).Hex()
+
+	case abi.FixedBytesTy:
+		return hexutil.Encode(g.randomBytes(t.Size))
+
+	case abi.BytesTy:
+		return hexutil.Encode(g.randomBytes(g.rnd.Intn(70)))
+
+	case abi.StringTy:
+		const alphabet = "abcdefghijklmnopqrstuvwxyz ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789é€😀"
+		runes := []rune(alphabet)
+		s := make([]rune, g.rnd.Intn(40))
+		for i := range s {
+			s[i] = runes[g.rnd.Intn(len(runes))]
+		}
+		return string(s)
+
+	case abi.SliceTy, abi.ArrayTy:
+		n := t.Size
+		if t.T == abi.SliceTy {
+			n = g.rnd.Intn(4)
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			items[i] = g.randomValue(*t.Elem)
+		}
+		return items
+
+	case abi.TupleTy:
+		obj := make(orderedObject, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			obj[i] = field{t.TupleRawNames[i], g.randomValue(*elem)}
+		}
+		return obj
+	}
+	panic(fmt.Sprintf("unsupported type %s", t.String()))
+}
+
+func (g *generator) randomBytes(n int) []byte {
+	b := make([]byte, n)
+	g.rnd.Read(b)
+	return b
+}
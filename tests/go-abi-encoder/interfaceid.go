@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// interfaceID is the JSON output of the interface mode: an ERC-165 interface
+// ID and the functions whose selectors were XORed into it.
+type interfaceID struct {
+	Name        string              `json:"name,omitempty"`
+	InterfaceID string              `json:"interfaceId"`
+	Functions   []interfaceFunction `json:"functions"`
+}
+
+type interfaceFunction struct {
+	Signature string `json:"signature"`
+	Selector  string `json:"selector"`
+}
+
+// referenceInterface is a standard interface with its published ID, which
+// the computed ID is checked against.
+type referenceInterface struct {
+	name       string
+	id         string
+	signatures []string
+}
+
+var referenceInterfaces = []referenceInterface{
+	{"ERC-165", "0x01ffc9a7", []string{
+		"supportsInterface(bytes4)",
+	}},
+	{"ERC-20", "0x36372b07", []string{
+		"totalSupply()",
+		"balanceOf(address)",
+		"transfer(address,uint256)",
+		"transferFrom(address,address,uint256)",
+		"approve(address,uint256)",
+		"allowance(address,address)",
+	}},
+	{"ERC-721", "0x80ac58cd", []string{
+		"balanceOf(address)",
+		"ownerOf(uint256)",
+		"safeTransferFrom(address,address,uint256,bytes)",
+		"safeTransferFrom(address,address,uint256)",
+		"transferFrom(address,address,uint256)",
+		"approve(address,uint256)",
+		"setApprovalForAll(address,bool)",
+		"getApproved(uint256)",
+		"isApprovedForAll(address,address)",
+	}},
+	{"ERC-1155", "0xd9b67a26", []string{
+		"safeTransferFrom(address,address,uint256,uint256,bytes)",
+		"safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)",
+		"balanceOf(address,uint256)",
+		"balanceOfBatch(address[],uint256[])",
+		"setApprovalForAll(address,bool)",
+		"isApprovedForAll(address,address)",
+	}},
+}
+
+// runInterfaceID prints the ERC-165 interface ID of the functions of an ABI
+// file, or of the functions listed in functions, a comma separated list of
+// names (matching every overload) and signatures. Without an ABI it prints
+// the reference IDs of the standard interfaces.
+func runInterfaceID(abiPath, functions string) {
+	if abiPath == "" {
+		ids := make([]interfaceID, len(referenceInterfaces))
+		for i, ref := range referenceInterfaces {
+			ids[i] = xorSelectors(ref.signatures)
+			ids[i].Name = ref.name
+			if ids[i].InterfaceID != ref.id {
+				fatalf("Error: %s computes to %s, expected %s", ref.name, ids[i].InterfaceID, ref.id)
+			}
+		}
+		printJSON(ids)
+		return
+	}
+
+	contract := mustLoadABI(abiPath)
+	sigs, err := selectFunctions(contract, functions)
+	if err != nil {
+		fatalf("Error selecting functions: %v", err)
+	}
+	printJSON(xorSelectors(sigs))
+}
+
+// selectFunctions returns the canonical signatures of the chosen functions,
+// or of every function when list is empty.
+func selectFunctions(contract abi.ABI, list string) ([]string, error) {
+	seen := make(map[string]bool)
+	var sigs []string
+	add := func(m abi.Method) {
+		if !seen[m.Sig] {
+			seen[m.Sig] = true
+			sigs = append(sigs, m.Sig)
+		}
+	}
+
+	if strings.TrimSpace(list) == "" {
+		for _, m := range contract.Methods {
+			add(m)
+		}
+		return sigs, nil
+	}
+
+	for _, name := range splitTopLevel(list) {
+		found := false
+		if strings.Contains(name, "(") {
+			sig, err := parseSignature(name)
+			if err != nil {
+				return nil, err
+			}
+			want, err := newMethod(sig)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range contract.Methods {
+				if m.Sig == want.Sig {
+					add(m)
+					found = true
+				}
+			}
+		} else {
+			for _, m := range contract.Methods {
+				if m.RawName == name {
+					add(m)
+					found = true
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no function %q in ABI", name)
+		}
+	}
+	return sigs, nil
+}
+
+// xorSelectors XORs the selectors of canonical function signatures into an
+// interface ID. The functions are listed sorted by signature.
+func xorSelectors(sigs []string) interfaceID {
+	sorted := append([]string{}, sigs...)
+	sort.Strings(sorted)
+
+	var id [4]byte
+	out := interfaceID{Functions: []interfaceFunction{}}
+	for _, sig := range sorted {
+		selector := crypto.Keccak256([]byte(sig))[:4]
+		for i := range id {
+			id[i] ^= selector[i]
+		}
+		out.Functions = append(out.Functions, interfaceFunction{sig, hexutil.Encode(selector)})
+	}
+	out.InterfaceID = hexutil.Encode(id[:])
+	return out
+}
+
+// splitTopLevel splits s on the commas that are not inside parentheses, so
+// that signatures can be listed alongside names.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
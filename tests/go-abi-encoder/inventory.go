@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// inventoryEntry describes one function, event or error of an ABI. Functions
+// and errors are identified by a 4-byte selector, events by a 32-byte topic.
+type inventoryEntry struct {
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	Signature       string `json:"signature"`
+	Selector        string `json:"selector,omitempty"`
+	Topic           string `json:"topic,omitempty"`
+	StateMutability string `json:"stateMutability,omitempty"`
+}
+
+// runInventory prints the canonical signature and identifier of every
+// function, event and error in an ABI file, as JSON or CSV.
+func runInventory(abiPath, format string) {
+	contract := mustLoadABI(abiPath)
+	entries := inventory(contract)
+
+	switch format {
+	case "json":
+		printJSON(entries)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"kind", "name", "signature", "selector", "topic", "stateMutability"})
+		for _, e := range entries {
+			w.Write([]string{e.Kind, e.Name, e.Signature, e.Selector, e.Topic, e.StateMutability})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			fatalf("Error writing CSV: %v", err)
+		}
+	default:
+		fatalf("Unknown format %q, use json or csv", format)
+	}
+}
+
+// inventory lists the ABI entries ordered by kind and then signature, so that
+// the output is stable across runs.
+func inventory(contract abi.ABI) []inventoryEntry {
+	var entries []inventoryEntry
+	for _, m := range contract.Methods {
+		entries = append(entries, inventoryEntry{
+			Kind:            "function",
+			Name:            m.RawName,
+			Signature:       m.Sig,
+			Selector:        hexutil.Encode(m.ID),
+			StateMutability: m.StateMutability,
+		})
+	}
+	for _, e := range contract.Events {
+		entries = append(entries, inventoryEntry{
+			Kind:      "event",
+			Name:      e.RawName,
+			Signature: e.Sig,
+			Topic:     e.ID.Hex(),
+		})
+	}
+	for _, e := range contract.Errors {
+		entries = append(entries, inventoryEntry{
+			Kind:      "error",
+			Name:      e.Name,
+			Signature: e.Sig,
+			Selector:  hexutil.Encode(e.ID[:4]),
+		})
+	}
+
+	kindOrder := map[string]int{"function": 0, "event": 1, "error": 2}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return kindOrder[entries[i].Kind] < kindOrder[entries[j].Kind]
+		}
+		return entries[i].Signature < entries[j].Signature
+	})
+	return entries
+}
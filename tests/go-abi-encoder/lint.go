@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// lintProblem is one finding of the lint mode. Path is a JSONPath into the
+// ABI array, Code a stable machine-readable identifier and Severity either
+// "error", for documents geth or the C# AbiJsonReader rejects or misreads,
+// or "warning".
+type lintProblem struct {
+	Path     string `json:"path"`
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// linter collects the problems of one ABI document.
+type linter struct {
+	problems []lintProblem
+}
+
+var (
+	entryTypes = map[string]bool{
+		"function": true, "constructor": true, "event": true, "error": true, "fallback": true, "receive": true,
+	}
+	stateMutabilities = map[string]bool{"pure": true, "view": true, "nonpayable": true, "payable": true}
+	identifierPattern = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*$`)
+)
+
+// runLint checks an ABI file and prints its problems as JSON, exiting with
+// status 1 when any of them is an error.
+func runLint(abiPath string) {
+	if abiPath == "" {
+		fatalf("Error: No ABI file provided, use --abi")
+	}
+	data, err := readABIJSON(abiPath)
+	if err != nil {
+		fatalf("Error reading ABI %s: %v", abiPath, err)
+	}
+
+	problems := lintABI(data)
+	printJSON(problems)
+	for _, p := range problems {
+		if p.Severity == "error" {
+			os.Exit(1)
+		}
+	}
+}
+
+// lintABI checks the entries of an ABI array one by one, then the document
+// as a whole for duplicate overloads, and finally has geth parse it, so that
+// anything geth rejects is reported even when no specific check caught it.
+func lintABI(data []byte) []lintProblem {
+	l := &linter{problems: []lintProblem{}}
+
+	var doc interface{}
+	if err := unmarshalJSON(data, &doc); err != nil {
+		l.errorf("$", "invalid-json", "%v", err)
+		return l.problems
+	}
+	entries, ok := doc.([]interface{})
+	if !ok {
+		l.errorf("$", "not-an-array", "an ABI must be a JSON array, got %s", jsonKind(doc))
+		return l.problems
+	}
+
+	signatures := make(map[string]string)
+	for i, e := range entries {
+		path := fmt.Sprintf("$[%d]", i)
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			l.errorf(path, "invalid-entry", "an ABI entry must be an object, got %s", jsonKind(e))
+			continue
+		}
+		sig := l.entry(path, entry)
+		if sig == "" {
+			continue
+		}
+		if first, ok := signatures[sig]; ok {
+			l.errorf(path, "duplicate-overload", "%s is already declared at %s", sig, first)
+		} else {
+			signatures[sig] = path
+		}
+	}
+
+	// Errors already explain why geth would reject the document, so it is
+	// only parsed when there are none; warnings alone must not skip it.
+	if !l.hasErrors() {
+		if _, err := abi.JSON(bytes.NewReader(data)); err != nil {
+			l.errorf("$", "geth-rejected", "%v", err)
+		}
+	}
+	return l.problems
+}
+
+// entry checks one ABI entry and returns its kind-qualified canonical
+// signature, e.g. "function transfer(address,uint256)", for the duplicate
+// check, or "" when it has none or is broken.
+func (l *linter) entry(path string, entry map[string]interface{}) string {
+	typ, ok := entry["type"].(string)
+	switch {
+	case entry["type"] == nil:
+		l.errorf(path+".type", "missing-type", "entry has no type; AbiJsonReader requires one")
+		return ""
+	case !ok || !entryTypes[typ]:
+		l.errorf(path+".type", "unknown-entry-type", "unknown entry type %v", entry["type"])
+		return ""
+	}
+
+	name, _ := entry["name"].(string)
+	switch typ {
+	case "function", "event", "error":
+		if !identifierPattern.MatchString(name) {
+			l.errorf(path+".name", "invalid-name", "%s needs a valid name, got %q", typ, name)
+		}
+	}
+
+	if sm, ok := entry["stateMutability"]; ok {
+		s, _ := sm.(string)
+		switch {
+		case typ == "event" || typ == "error":
+			l.warnf(path+".stateMutability", "unexpected-state-mutability", "%s entries have no stateMutability", typ)
+		case !stateMutabilities[s]:
+			l.errorf(path+".stateMutability", "invalid-state-mutability", "stateMutability %v is not pure, view, nonpayable or payable", sm)
+		case typ == "constructor" && (s == "pure" || s == "view"):
+			l.errorf(path+".stateMutability", "invalid-state-mutability", "a constructor cannot be %s", s)
+		case typ == "receive" && s != "payable":
+			l.errorf(path+".stateMutability", "invalid-state-mutability", "receive must be payable, got %s", s)
+		case typ == "fallback" && s != "payable" && s != "nonpayable":
+			l.errorf(path+".stateMutability", "invalid-state-mutability", "fallback must be payable or nonpayable, got %s", s)
+		}
+	} else if typ != "event" && typ != "error" {
+		l.warnf(path, "missing-state-mutability", "%s has no stateMutability; pre-0.5 constant and payable flags are not read by AbiJsonReader", typ)
+	}
+
+	inputs := l.params(path+".inputs", entry["inputs"], typ == "event")
+	if outputs, ok := entry["outputs"]; ok {
+		if typ != "function" {
+			l.warnf(path+".outputs", "unexpected-outputs", "%s entries have no outputs", typ)
+		}
+		l.params(path+".outputs", outputs, false)
+	}
+
+	if typ == "event" {
+		anonymous, _ := entry["anonymous"].(bool)
+		indexed := 0
+		for _, p := range asArray(entry["inputs"]) {
+			if m, ok := p.(map[string]interface{}); ok && m["indexed"] == true {
+				indexed++
+			}
+		}
+		if max := 3 + boolInt(anonymous); indexed > max {
+			l.errorf(path+".inputs", "too-many-indexed", "%d indexed parameters, at most %d are allowed", indexed, max)
+		}
+	}
+
+	if inputs == nil || (typ != "function" && typ != "event" && typ != "error") {
+		return ""
+	}
+	return fmt.Sprintf("%s %s(%s)", typ, name, strings.Join(inputs, ","))
+}
+
+// params checks a parameter list and returns the canonical types of the
+// parameters, or nil if any is broken.
+func (l *linter) params(path string, v interface{}, event bool) []string {
+	if v == nil {
+		return []string{}
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		l.errorf(path, "invalid-parameters", "parameters must be an array, got %s", jsonKind(v))
+		return nil
+	}
+
+	types := make([]string, 0, len(list))
+	for i, p := range list {
+		t := l.param(fmt.Sprintf("%s[%d]", path, i), p, event, false)
+		if t == "" {
+			types = nil
+		} else if types != nil {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// param checks one parameter, or tuple component when component is set, and
+// returns its canonical type, or "" if it is broken.
+func (l *linter) param(path string, v interface{}, event, component bool) string {
+	p, ok := v.(map[string]interface{})
+	if !ok {
+		l.errorf(path, "invalid-parameter", "a parameter must be an object, got %s", jsonKind(v))
+		return ""
+	}
+
+	name, hasName := p["name"].(string)
+	switch {
+	case component && name == "":
+		l.errorf(path+".name", "unnamed-component", "tuple components must be named; geth rejects unnamed components")
+	case !hasName:
+		l.errorf(path, "missing-name", "parameter has no name property; AbiJsonReader requires one, even if empty")
+	case name != "" && !identifierPattern.MatchString(name):
+		l.errorf(path+".name", "invalid-name", "invalid parameter name %q", name)
+	case component && strings.Trim(name, "_") == "":
+		// geth turns component names into Go struct fields with
+		// abi.ToCamelCase, which leaves nothing of "_" or "__".
+		l.errorf(path+".name", "invalid-name", "tuple component name %q is only underscores; geth rejects it", name)
+	}
+
+	if p["indexed"] == true && !event {
+		l.warnf(path+".indexed", "unexpected-indexed", "only event parameters can be indexed")
+	}
+
+	typ, ok := p["type"].(string)
+	if !ok || typ == "" {
+		l.errorf(path+".type", "missing-type", "parameter has no type")
+		return ""
+	}
+
+	base := arraySuffix.ReplaceAllString(typ, "")
+	components, hasComponents := p["components"]
+	var canonical string
+	if base == "tuple" {
+		if !hasComponents {
+			l.errorf(path, "missing-components", "%s has no components", typ)
+			return ""
+		}
+		list, ok := components.([]interface{})
+		if !ok {
+			l.errorf(path+".components", "invalid-parameters", "components must be an array, got %s", jsonKind(components))
+			return ""
+		}
+		inner := make([]string, len(list))
+		for i, c := range list {
+			if inner[i] = l.param(fmt.Sprintf("%s.components[%d]", path, i), c, false, true); inner[i] == "" {
+				return ""
+			}
+		}
+		canonical = "(" + strings.Join(inner, ",") + ")" + strings.TrimPrefix(typ, "tuple")
+	} else {
+		if hasComponents {
+			l.warnf(path+".components", "unexpected-components", "components are ignored on non-tuple type %s", typ)
+		}
+		if alias := canonicalElementary(base); alias != base {
+			l.errorf(path+".type", "non-canonical-type", "%s must be written %s in ABI JSON; geth rejects the alias", base, alias)
+			typ = alias + strings.TrimPrefix(typ, base)
+		}
+		t, err := abi.NewType(typ, "", nil)
+		if err == nil {
+			err = checkSizes(t)
+		}
+		if base == "bytes0" {
+			// geth reads bytes0 as bytes.
+			err = fmt.Errorf("bytes0 is not bytes1 to bytes32")
+		}
+		if err != nil {
+			l.errorf(path+".type", "invalid-type", "invalid type %q: %v", typ, err)
+			return ""
+		}
+		canonical = t.String()
+	}
+
+	if internal, ok := p["internalType"].(string); ok {
+		l.internalType(path+".internalType", typ, internal)
+	}
+	return canonical
+}
+
+// internalType checks that a parameter's internalType agrees with its type:
+// structs are tuples, contracts and "address payable" are addresses, enums are
+// uint8, elementary internal types equal the type, and array suffixes match.
+func (l *linter) internalType(path, typ, internal string) {
+	base := arraySuffix.ReplaceAllString(typ, "")
+	internalBase := arraySuffix.ReplaceAllString(internal, "")
+	mismatch := func(want string) {
+		l.errorf(path, "internal-type-mismatch", "internalType %q does not match type %q: %s", internal, typ, want)
+	}
+
+	if strings.TrimPrefix(typ, base) != strings.TrimPrefix(internal, internalBase) {
+		mismatch("the array dimensions differ")
+		return
+	}
+	switch {
+	case strings.HasPrefix(internalBase, "struct "):
+		if base != "tuple" {
+			mismatch("a struct must be a tuple")
+		}
+	case base == "tuple":
+		mismatch("a tuple's internalType must be a struct")
+	case strings.HasPrefix(internalBase, "contract "), internalBase == "address payable":
+		if base != "address" {
+			mismatch("a contract or address payable must be an address")
+		}
+	case strings.HasPrefix(internalBase, "enum "):
+		if base != "uint8" {
+			mismatch("an enum must be a uint8")
+		}
+	default:
+		// Elementary internal types must equal the type; anything else is
+		// taken to be a user-defined value type.
+		if t, err := abi.NewType(internalBase, "", nil); err == nil && t.String() != base {
+			mismatch("the elementary types differ")
+		}
+	}
+}
+
+// checkSizes rejects the sizes geth's type parser lets through but Solidity
+// and AbiTypes do not: integer widths other than 8 to 256 in steps of 8,
+// bytesN outside 1 to 32 and zero-length fixed arrays.
+func checkSizes(t abi.Type) error {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		if t.Size < 8 || t.Size > 256 || t.Size%8 != 0 {
+			return fmt.Errorf("integer width %d is not a multiple of 8 from 8 to 256", t.Size)
+		}
+	case abi.FixedBytesTy:
+		if t.Size < 1 || t.Size > 32 {
+			return fmt.Errorf("bytes%d is not bytes1 to bytes32", t.Size)
+		}
+	case abi.ArrayTy, abi.SliceTy:
+		if t.T == abi.ArrayTy && t.Size == 0 {
+			return fmt.Errorf("fixed arrays must have at least one element")
+		}
+		return checkSizes(*t.Elem)
+	}
+	return nil
+}
+
+func (l *linter) hasErrors() bool {
+	for _, p := range l.problems {
+		if p.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *linter) errorf(path, code, format string, args ...interface{}) {
+	l.problems = append(l.problems, lintProblem{path, code, "error", fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) warnf(path, code, format string, args ...interface{}) {
+	l.problems = append(l.problems, lintProblem{path, code, "warning", fmt.Sprintf(format, args...)})
+}
+
+func asArray(v interface{}) []interface{} {
+	list, _ := v.([]interface{})
+	return list
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// jsonKind names the JSON type of a decoded value for error messages.
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case string:
+		return "a string"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return "a number"
+}
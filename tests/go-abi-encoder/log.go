@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// decodedLog is the JSON output of the log mode.
+type decodedLog struct {
+	Event     string        `json:"event"`
+	Signature string        `json:"signature"`
+	Values    orderedObject `json:"values"`
+}
+
+// runLog decodes an event log against the events of a contract ABI.
+func runLog(abiPath, eventName, topicList, dataHex string) {
+	contract := mustLoadABI(abiPath)
+
+	var topics []common.Hash
+	for _, t := range strings.Split(topicList, ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		b, err := hexutil.Decode(t)
+		if err != nil || len(b) != common.HashLength {
+			fatalf("Error: invalid topic %q", t)
+		}
+		topics = append(topics, common.BytesToHash(b))
+	}
+
+	data, err := hexutil.Decode(dataHex)
+	if err != nil {
+		fatalf("Error decoding hex data: %v", err)
+	}
+
+	decoded, err := decodeLog(contract, eventName, topics, data)
+	if err != nil {
+		fatalf("Decoding error: %v", err)
+	}
+
+	printJSON(decoded)
+}
+
+// decodeLog finds the event a log was emitted by and decodes its fields in
+// declaration order. The event is matched by topic0 unless eventName is given,
+// which is the only way to decode anonymous events. Indexed strings, bytes,
+// arrays and structs are only present as their hash, which is returned as is.
+func decodeLog(contract abi.ABI, eventName string, topics []common.Hash, data []byte) (decodedLog, error) {
+	var event *abi.Event
+	if eventName != "" {
+		e, ok := contract.Events[eventName]
+		if !ok {
+			return decodedLog{}, fmt.Errorf("no event named %q in ABI", eventName)
+		}
+		event = &e
+	} else {
+		if len(topics) == 0 {
+			return decodedLog{}, fmt.Errorf("log has no topics; use --event for anonymous events")
+		}
+		e, err := contract.EventByID(topics[0])
+		if err != nil {
+			return decodedLog{}, fmt.Errorf("no event in ABI matches topic0 %s", topics[0].Hex())
+		}
+		event = e
+	}
+
+	if !event.Anonymous {
+		if len(topics) == 0 || topics[0] != event.ID {
+			return decodedLog{}, fmt.Errorf("topic0 does not match %s", event.Sig)
+		}
+		topics = topics[1:]
+	}
+
+	nonIndexed, err := event.Inputs.NonIndexed().Unpack(data)
+	if err != nil {
+		return decodedLog{}, fmt.Errorf("data: %v", err)
+	}
+
+	values := make(orderedObject, 0, len(event.Inputs))
+	for _, input := range event.Inputs {
+		if !input.Indexed {
+			values = append(values, field{input.Name, jsonValue(input.Type, nil, reflect.ValueOf(nonIndexed[0]))})
+			nonIndexed = nonIndexed[1:]
+			continue
+		}
+
+		if len(topics) == 0 {
+			return decodedLog{}, fmt.Errorf("missing topic for indexed %s", input.Name)
+		}
+		topic := topics[0]
+		topics = topics[1:]
+
+		switch input.Type.T {
+		case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+			values = append(values, field{input.Name, topic.Hex()})
+		default:
+			v, err := abi.Arguments{{Type: input.Type}}.Unpack(topic[:])
+			if err != nil {
+				return decodedLog{}, fmt.Errorf("topic for %s: %v", input.Name, err)
+			}
+			values = append(values, field{input.Name, jsonValue(input.Type, nil, reflect.ValueOf(v[0]))})
+		}
+	}
+
+	if len(topics) > 0 {
+		return decodedLog{}, fmt.Errorf("%d unexpected extra topics for %s", len(topics), event.Sig)
+	}
+
+	return decodedLog{Event: event.Name, Signature: event.Sig, Values: values}, nil
+}
@@ -1,268 +1,113 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"math/big"
+	"io"
 	"os"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 )
 
 func main() {
-	testNum := flag.Int("test", 0, "Test case number (1-21)")
+	testNum := flag.Int("test", 0, "Hand-written test case number (1-21)")
+	mode := flag.String("mode", "encode", "Mode: encode, decode, call, deploy, multicall, packed, event, log, revert, calldata, inventory, interface, lint, diff, slot, eas, fuzz, widths, zeros, strict, csharp, pocos")
+	input := flag.String("input", "", "JSON document (or pass it as the first positional argument)")
+	file := flag.String("file", "", "Read the JSON document from a file ('-' for stdin)")
+	abiPath := flag.String("abi", "", "Contract ABI JSON file")
+	newABIPath := flag.String("new", "", "Newer version of the --abi file (diff mode)")
+	event := flag.String("event", "", "Event name, instead of matching by topic0 (log mode)")
+	topics := flag.String("topics", "", "Comma separated log topics (log mode)")
+	hexData := flag.String("data", "0x", "Hex data (log, revert and calldata modes)")
+	method := flag.String("method", "", "Method name or signature in the --abi file (call mode)")
+	methodArgs := flag.String("args", "", "JSON array or object of method or constructor arguments (call and deploy modes)")
+	bytecode := flag.String("bytecode", "", "Creation bytecode hex, instead of the --abi artifact's bytecode (deploy mode)")
+	safeNames := flag.Bool("safe-names", false, "Key values by C# SafeName with _1, _2 suffixes for duplicates, as AbiDecodingResult does (decode mode)")
+	explain := flag.Bool("explain", false, "Annotate each 32-byte word with its role and parameter path (encode and call modes)")
+	functions := flag.String("functions", "", "Comma separated function names or signatures, instead of every function (interface mode)")
+	format := flag.String("format", "json", "Output format: json or csv (inventory mode)")
+	templatePath := flag.String("template", "", "text/template file, instead of the built-in AbiTestCases.cs.template (csharp mode)")
+	namespace := flag.String("namespace", "Evoq.Ethereum.Contracts", "C# namespace of the generated classes (pocos mode)")
+	seed := flag.Int64("seed", 1, "Random seed (fuzz mode)")
+	count := flag.Int("count", 10, "Number of cases to generate (fuzz mode)")
+	depth := flag.Int("depth", 3, "Maximum nesting of arrays and tuples (fuzz mode)")
 	flag.Parse()
 
-	if *testNum < 1 || *testNum > 21 {
-		fmt.Println("Please specify a test case with --test (1-21)")
-		os.Exit(1)
+	if *testNum != 0 {
+		runTestCase(*testNum)
+		return
 	}
 
-	var types []abi.Type
-	var values []interface{}
-
-	// Define ABI types
-	evmUint256, _ := abi.NewType("uint256", "", nil)
-	// abi_uint32, _ := abi.NewType("uint32", "", nil)
-	evmUint8, _ := abi.NewType("uint8", "", nil)
-	evmBoolType, _ := abi.NewType("bool", "", nil)
-	evmBytes, _ := abi.NewType("bytes", "", nil)
-	evmBytes10, _ := abi.NewType("bytes10", "", nil)
-	// bytes3, _ := abi.NewType("bytes3", "", nil)
-	// stringType, _ := abi.NewType("string", "", nil)
-
-	// Test cases
-	switch *testNum {
-	case 1: // foo(uint256) - 1
-		types = []abi.Type{evmUint256}
-		values = []interface{}{big.NewInt(1)}
-
-	case 2: // foo(bool) - true
-		types = []abi.Type{evmBoolType}
-		values = []interface{}{true}
-
-	case 3: // foo(uint8, uint256) - (1, 1)
-		types = []abi.Type{evmUint8, evmUint256}
-		values = []interface{}{uint8(1), big.NewInt(1)}
-
-	case 4: // foo(uint8[2]) - [1, 2]
-		uint8_2, _ := abi.NewType("uint8[2]", "", nil)
-		types = []abi.Type{uint8_2}
-		values = []interface{}{[2]uint8{1, 2}}
-
-	case 5: // foo(uint8[4][2]) - [[10, 20, 30, 40], [1, 2, 3, 4]]
-		uint8_4_2, _ := abi.NewType("uint8[4][2]", "", nil)
-		types = []abi.Type{uint8_4_2}
-		values = []interface{}{[2][4]uint8{{10, 20, 30, 40}, {1, 2, 3, 4}}}
-
-	case 6: // foo(uint8[3][2][1]) - [[[1, 2, 3], [1, 2, 3]]]
-		uint8_3_2_1, _ := abi.NewType("uint8[3][2][1]", "", nil)
-		types = []abi.Type{uint8_3_2_1}
-		values = []interface{}{[1][2][3]uint8{{{1, 2, 3}, {1, 2, 3}}}}
-
-	case 7: // foo((uint256 id, uint256 balance) account) - (3, 10)
-		tuple, _ := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
-			{Name: "id", Type: "uint256"},
-			{Name: "balance", Type: "uint256"},
-		})
-		types = []abi.Type{tuple}
-		values = []interface{}{struct {
-			ID      *big.Int `abi:"id"`
-			Balance *big.Int `abi:"balance"`
-		}{big.NewInt(3), big.NewInt(10)}}
-
-	case 8: // foo(bool isActive, (uint256 id, uint256 balance) account) - (true, (3, 10))
-		tuple, _ := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
-			{Name: "id", Type: "uint256"},
-			{Name: "balance", Type: "uint256"},
-		})
-		types = []abi.Type{evmBoolType, tuple}
-		values = []interface{}{
-			true,
-			struct {
-				ID      *big.Int `abi:"id"`
-				Balance *big.Int `abi:"balance"`
-			}{big.NewInt(3), big.NewInt(10)},
-		}
-
-	case 9: // foo((bool isActive, uint256 seenUnix) prof, (uint256 id, uint256 balance) account) - ((true, 20), (3, 10))
-		profTuple, _ := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
-			{Name: "isActive", Type: "bool"},
-			{Name: "seenUnix", Type: "uint256"},
-		})
-		acctTuple, _ := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
-			{Name: "id", Type: "uint256"},
-			{Name: "balance", Type: "uint256"},
-		})
-		types = []abi.Type{profTuple, acctTuple}
-		values = []interface{}{
-			struct {
-				IsActive bool     `abi:"isActive"`
-				SeenUnix *big.Int `abi:"seenUnix"`
-			}{true, big.NewInt(20)},
-			struct {
-				ID      *big.Int `abi:"id"`
-				Balance *big.Int `abi:"balance"`
-			}{big.NewInt(3), big.NewInt(10)},
-		}
-
-	case 10: // foo(((bool isActive, uint256 seenUnix) prof, uint256 id, uint256 balance) account) - ((true, 20), 3, 10)
-		nestedTuple, _ := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
-			{Name: "prof", Type: "tuple", Components: []abi.ArgumentMarshaling{
-				{Name: "isActive", Type: "bool"},
-				{Name: "seenUnix", Type: "uint256"},
-			}},
-			{Name: "id", Type: "uint256"},
-			{Name: "balance", Type: "uint256"},
-		})
-		types = []abi.Type{nestedTuple}
-		values = []interface{}{
-			struct {
-				Prof struct {
-					IsActive bool     `abi:"isActive"`
-					SeenUnix *big.Int `abi:"seenUnix"`
-				} `abi:"prof"`
-				ID      *big.Int `abi:"id"`
-				Balance *big.Int `abi:"balance"`
-			}{
-				Prof: struct {
-					IsActive bool     `abi:"isActive"`
-					SeenUnix *big.Int `abi:"seenUnix"`
-				}{true, big.NewInt(20)},
-				ID:      big.NewInt(3),
-				Balance: big.NewInt(10),
-			},
-		}
-
-	case 11: // foo(bytes) - [1]
-		types = []abi.Type{evmBytes}
-		values = []interface{}{[]byte{1}}
-
-	case 12: // foo(uint8[]) - [1, 2]
-		uint8Dyn, _ := abi.NewType("uint8[]", "", nil)
-		types = []abi.Type{uint8Dyn}
-		values = []interface{}{[]uint8{1, 2}}
-
-	case 13: // foo(uint8[2][]) - [[1, 2], [3, 4]]
-		uint8_2Dyn, _ := abi.NewType("uint8[2][]", "", nil)
-		types = []abi.Type{uint8_2Dyn}
-		values = []interface{}{[][2]uint8{{1, 2}, {3, 4}}}
-
-	case 14: // foo(uint8[][]) - [[1, 2], [3, 4]]
-		uint8DynDyn, _ := abi.NewType("uint8[][]", "", nil)
-		types = []abi.Type{uint8DynDyn}
-		values = []interface{}{[][]uint8{{1, 2}, {3, 4}}}
-
-	case 15: // foo(bool isActive, (string id, uint256 balance) account) - (true, ("abc", 9))
-		tuple, _ := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
-			{Name: "id", Type: "string"},
-			{Name: "balance", Type: "uint256"},
-		})
-		types = []abi.Type{evmBoolType, tuple}
-		values = []interface{}{
-			true,
-			struct {
-				ID      string   `abi:"id"`
-				Balance *big.Int `abi:"balance"`
-			}{"abc", big.NewInt(9)},
-		}
-
-	case 16: // foo(bool isActive, ((string id, string name) user, uint256 balance) account) - (true, (("a", "abc"), 9))
-		nestedTuple, _ := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
-			{Name: "user", Type: "tuple", Components: []abi.ArgumentMarshaling{
-				{Name: "id", Type: "string"},
-				{Name: "name", Type: "string"},
-			}},
-			{Name: "balance", Type: "uint256"},
-		})
-		types = []abi.Type{evmBoolType, nestedTuple}
-		values = []interface{}{
-			true,
-			struct {
-				User struct {
-					ID   string `abi:"id"`
-					Name string `abi:"name"`
-				} `abi:"user"`
-				Balance *big.Int `abi:"balance"`
-			}{
-				User: struct {
-					ID   string `abi:"id"`
-					Name string `abi:"name"`
-				}{"a", "abc"},
-				Balance: big.NewInt(9),
-			},
-		}
-
-	case 17: // bar(bytes3[2]) - ["abc", "def"]
-		bytes3_2, _ := abi.NewType("bytes3[2]", "", nil)
-		types = []abi.Type{bytes3_2}
-		values = []interface{}{[2][3]byte{{'a', 'b', 'c'}, {'d', 'e', 'f'}}}
-
-	case 18: // baz(uint256 x, bool y) - (69, true)
-		types = []abi.Type{evmUint256, evmBoolType}
-		values = []interface{}{big.NewInt(69), true}
-
-	case 19: // sam(bytes, bool, uint[]) - ("dave", true, [1, 2, 3])
-		uintDyn, err := abi.NewType("uint256[]", "", nil)
-		if err != nil {
-			fmt.Printf("Failed to create uint256[] type: %v\n", err)
-			os.Exit(1)
-		}
-		types = []abi.Type{evmBytes, evmBoolType, uintDyn}
-		uintValues := []*big.Int{
-			big.NewInt(1),
-			big.NewInt(2),
-			big.NewInt(3),
+	switch *mode {
+	case "encode":
+		runEncode(readDocument(*input, *file), *explain)
+	case "decode":
+		runDecode(readDocument(*input, *file), *safeNames)
+	case "call":
+		if *abiPath != "" {
+			runMethodCall(*abiPath, *method, *methodArgs, *explain)
+		} else {
+			runCall(readDocument(*input, *file), *explain)
 		}
-		values = []interface{}{
-			[]byte("dave"),
-			true,
-			uintValues,
-		}
-
-	case 20: // foo(uint256, uint32[], bytes10, bytes) - (0x123, [0x456, 0x789], "1234567890", "Hello, world!")
-		uint32Dyn, _ := abi.NewType("uint32[]", "", nil)
-		types = []abi.Type{evmUint256, uint32Dyn, evmBytes10, evmBytes}
-		values = []interface{}{
-			big.NewInt(0x123),
-			[]uint32{0x456, 0x789},
-			[10]byte{'1', '2', '3', '4', '5', '6', '7', '8', '9', '0'},
-			[]byte("Hello, world!"),
-		}
-
-	case 21: // foo(uint256 orderNumber, (bool isLatte, bool hasMilk, bool hasSugar)[] coffeeOrders) - (42, [(true, false, true), (false, true, false)])
-		// Define the tuple type components
-		tupleComponents := []abi.ArgumentMarshaling{
-			{Name: "isLatte", Type: "bool"},
-			{Name: "hasMilk", Type: "bool"},
-			{Name: "hasSugar", Type: "bool"},
-		}
-
-		// Define the array of tuples type
-		coffeeOrdersArrayType, _ := abi.NewType("tuple[]", "", tupleComponents)
-
-		// Define the types for the function arguments
-		types = []abi.Type{evmUint256, coffeeOrdersArrayType}
-
-		// Create the coffee orders
-		type CoffeeOrder struct {
-			IsLatte  bool `abi:"isLatte"`
-			HasMilk  bool `abi:"hasMilk"`
-			HasSugar bool `abi:"hasSugar"`
-		}
-
-		coffeeOrders := []CoffeeOrder{
-			{IsLatte: true, HasMilk: false, HasSugar: true},
-			{IsLatte: false, HasMilk: true, HasSugar: false},
+	case "deploy":
+		runDeploy(*abiPath, *bytecode, *methodArgs)
+	case "multicall":
+		runMulticall(readDocument(*input, *file))
+	case "packed":
+		runPacked(readDocument(*input, *file))
+	case "event":
+		runEvent(readDocument(*input, *file))
+	case "log":
+		runLog(*abiPath, *event, *topics, *hexData)
+	case "revert":
+		runRevert(*abiPath, *hexData)
+	case "calldata":
+		runCalldata(*abiPath, *hexData)
+	case "inventory":
+		runInventory(*abiPath, *format)
+	case "interface":
+		runInterfaceID(*abiPath, *functions)
+	case "slot":
+		runSlot(readDocument(*input, *file))
+	case "eas":
+		runEAS(readDocument(*input, *file))
+	case "lint":
+		runLint(*abiPath)
+	case "diff":
+		runDiff(*abiPath, *newABIPath)
+	case "fuzz":
+		runFuzz(*seed, *count, *depth)
+	case "widths":
+		runWidths()
+	case "zeros":
+		runZeros()
+	case "strict":
+		if *input == "" && *file == "" && flag.NArg() == 0 {
+			runStrict(nil)
+		} else {
+			runStrict(readDocument(*input, *file))
 		}
+	case "csharp":
+		runCSharp(readDocument(*input, *file), *templatePath)
+	case "pocos":
+		runPocos(*abiPath, *namespace)
+	default:
+		fmt.Printf("Unknown mode %q\n", *mode)
+		flag.Usage()
+		os.Exit(1)
+	}
+}
 
-		// Set the values for the function arguments
-		values = []interface{}{
-			big.NewInt(42),
-			coffeeOrders,
-		}
+// runTestCase encodes one of the original hand-written cases.
+func runTestCase(n int) {
+	if n < 1 || n > 21 {
+		fatalf("Please specify a test case with --test (1-21)")
 	}
 
+	types, values := testCase(n)
+
 	// Create ABI arguments
 	args := abi.Arguments{}
 	for _, t := range types {
@@ -272,10 +117,47 @@ func main() {
 	// Encode
 	encoded, err := args.Pack(values...)
 	if err != nil {
-		fmt.Printf("Encoding error: %v\n", err)
-		os.Exit(1)
+		fatalf("Encoding error: %v", err)
 	}
 
 	// Output as hex
 	fmt.Printf("0x%x\n", encoded)
 }
+
+// readDocument returns the JSON document given by --file, --input or the
+// first positional argument, in that order of preference.
+func readDocument(input, file string) []byte {
+	switch {
+	case file == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fatalf("Error reading stdin: %v", err)
+		}
+		return data
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fatalf("Error reading %s: %v", file, err)
+		}
+		return data
+	case input != "":
+		return []byte(input)
+	case flag.NArg() > 0:
+		return []byte(flag.Arg(0))
+	}
+	fatalf("Error: No JSON document provided, use --input, --file or a positional argument")
+	return nil
+}
+
+// unmarshalJSON decodes data into v, keeping numbers as json.Number so that
+// integers wider than 53 bits survive intact.
+func unmarshalJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Printf(format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// aggregate3Signature is Multicall3's batching entry point, deployed at
+// 0xcA11bde05977b3631167028862bE2a173976CA11 on most chains.
+const aggregate3Signature = "aggregate3((address target, bool allowFailure, bytes callData)[] calls) payable returns ((bool success, bytes returnData)[] returnData)"
+
+// multicallDocument is the JSON input of the multicall mode, for example:
+//
+//	{"calls": [
+//	  {"target": "0x...", "signature": "balanceOf(address owner) returns (uint256)",
+//	   "args": ["0x..."], "allowFailure": true},
+//	  {"target": "0x...", "abi": "Token.json", "method": "symbol"}],
+//	 "returnData": "0x..."}
+//
+// Without returnData it encodes the aggregate3 calldata; with it, it decodes
+// the (bool,bytes)[] returned by aggregate3.
+type multicallDocument struct {
+	Calls      []multicallEntry `json:"calls"`
+	ReturnData string           `json:"returnData"`
+}
+
+// multicallEntry is one batched call. The method is given either as a
+// human-readable signature, whose "returns (...)" clause declares the outputs,
+// or as a method name or signature in an ABI file. args is an array of values
+// or an object keyed by parameter name, as for the call mode.
+type multicallEntry struct {
+	Target       string      `json:"target"`
+	Signature    string      `json:"signature"`
+	ABI          string      `json:"abi"`
+	Method       string      `json:"method"`
+	Args         interface{} `json:"args"`
+	AllowFailure bool        `json:"allowFailure"`
+}
+
+// multicallResult is the decoded result of one batched call. Values holds the
+// method's outputs when the call succeeded, Revert the decoded revert data
+// when it failed and Error why either could not be decoded.
+type multicallResult struct {
+	Target     string         `json:"target"`
+	Method     string         `json:"method"`
+	Success    bool           `json:"success"`
+	ReturnData string         `json:"returnData"`
+	Values     orderedObject  `json:"values,omitempty"`
+	Revert     *decodedRevert `json:"revert,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// multicallCall is a resolved entry.
+type multicallCall struct {
+	entry    multicallEntry
+	contract *abi.ABI
+	method   abi.Method
+	outputs  []abi.ArgumentMarshaling
+	calldata []byte
+}
+
+// runMulticall encodes the aggregate3 calldata of a batch, or decodes the
+// batch's return data when the document has it.
+func runMulticall(data []byte) {
+	var doc multicallDocument
+	if err := unmarshalJSON(data, &doc); err != nil {
+		fatalf("Error parsing JSON document: %v", err)
+	}
+
+	calls, err := resolveMulticall(doc.Calls)
+	if err != nil {
+		fatalf("Error resolving calls: %v", err)
+	}
+
+	if doc.ReturnData == "" {
+		calldata, err := encodeMulticall(calls)
+		if err != nil {
+			fatalf("Encoding error: %v", err)
+		}
+		fmt.Printf("0x%x\n", calldata)
+		return
+	}
+
+	returnData, err := hexutil.Decode(doc.ReturnData)
+	if err != nil {
+		fatalf("Error decoding hex return data: %v", err)
+	}
+	results, err := decodeMulticall(calls, returnData)
+	if err != nil {
+		fatalf("Decoding error: %v", err)
+	}
+	printJSON(results)
+}
+
+// aggregate3 returns the aggregate3 method.
+func aggregate3() abi.Method {
+	sig, err := parseSignature(aggregate3Signature)
+	if err != nil {
+		panic(err)
+	}
+	method, err := newMethod(sig)
+	if err != nil {
+		panic(err)
+	}
+	return method
+}
+
+// resolveMulticall resolves the method of every entry and encodes its
+// calldata. ABI files shared by several entries are loaded once.
+func resolveMulticall(entries []multicallEntry) ([]multicallCall, error) {
+	contracts := make(map[string]*abi.ABI)
+	calls := make([]multicallCall, len(entries))
+	for i, entry := range entries {
+		call := multicallCall{entry: entry}
+		raw := entry.Args
+		if raw == nil {
+			raw = []interface{}{}
+		}
+
+		var goVals []interface{}
+		switch {
+		case entry.Signature != "" && entry.ABI == "":
+			sig, err := parseSignature(entry.Signature)
+			if err != nil {
+				return nil, fmt.Errorf("calls[%d]: %v", i, err)
+			}
+			if call.method, err = newMethod(sig); err != nil {
+				return nil, fmt.Errorf("calls[%d]: %v", i, err)
+			}
+			if goVals, err = methodGoValues(call.method, raw); err != nil {
+				return nil, fmt.Errorf("calls[%d] %s: %v", i, call.method.Sig, err)
+			}
+			call.outputs = sig.Outputs
+
+		case entry.ABI != "" && entry.Method != "":
+			contract, ok := contracts[entry.ABI]
+			if !ok {
+				c, err := loadABI(entry.ABI)
+				if err != nil {
+					return nil, fmt.Errorf("calls[%d]: loading ABI %s: %v", i, entry.ABI, err)
+				}
+				contract = &c
+				contracts[entry.ABI] = contract
+			}
+			call.contract = contract
+			var err error
+			if call.method, goVals, err = resolveMethod(*contract, entry.Method, raw); err != nil {
+				return nil, fmt.Errorf("calls[%d]: %v", i, err)
+			}
+
+		default:
+			return nil, fmt.Errorf("calls[%d]: give either a signature, or an abi file and a method", i)
+		}
+
+		packed, err := call.method.Inputs.Pack(goVals...)
+		if err != nil {
+			return nil, fmt.Errorf("calls[%d] %s: %v", i, call.method.Sig, err)
+		}
+		call.calldata = append(append([]byte{}, call.method.ID...), packed...)
+		calls[i] = call
+	}
+	return calls, nil
+}
+
+// encodeMulticall returns the aggregate3 calldata for the resolved calls.
+func encodeMulticall(calls []multicallCall) ([]byte, error) {
+	entries := make([]interface{}, len(calls))
+	for i, call := range calls {
+		entries[i] = map[string]interface{}{
+			"target":       call.entry.Target,
+			"allowFailure": call.entry.AllowFailure,
+			"callData":     hexutil.Encode(call.calldata),
+		}
+	}
+
+	method := aggregate3()
+	goVals, err := goValues(method.Inputs, []interface{}{entries})
+	if err != nil {
+		return nil, err
+	}
+	packed, err := method.Inputs.Pack(goVals...)
+	if err != nil {
+		return nil, err
+	}
+	return append(method.ID, packed...), nil
+}
+
+// decodeMulticall splits the (bool,bytes)[] returned by aggregate3 and
+// decodes each result by the outputs of its own method. Failed calls have
+// their revert data decoded instead, resolving custom errors against the
+// entry's ABI file when it has one.
+func decodeMulticall(calls []multicallCall, returnData []byte) ([]multicallResult, error) {
+	unpacked, err := aggregate3().Outputs.Unpack(returnData)
+	if err != nil {
+		return nil, err
+	}
+	list := reflect.ValueOf(unpacked[0])
+	if list.Len() != len(calls) {
+		return nil, fmt.Errorf("%d results for %d calls", list.Len(), len(calls))
+	}
+
+	results := make([]multicallResult, len(calls))
+	for i, call := range calls {
+		success := list.Index(i).Field(0).Bool()
+		data := list.Index(i).Field(1).Bytes()
+		result := multicallResult{
+			Target:     call.entry.Target,
+			Method:     call.method.Sig,
+			Success:    success,
+			ReturnData: hexutil.Encode(data),
+		}
+
+		if success {
+			values, err := call.method.Outputs.Unpack(data)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Values = make(orderedObject, len(values))
+				for j, output := range call.method.Outputs {
+					var components []abi.ArgumentMarshaling
+					if j < len(call.outputs) {
+						components = call.outputs[j].Components
+					}
+					result.Values[j] = field{safeName(output.Name, j), jsonValue(output.Type, components, reflect.ValueOf(values[j]))}
+				}
+			}
+		} else {
+			revert, err := decodeRevert(call.contract, data)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Revert = &revert
+			}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// runPacked prints the abi.encodePacked encoding of an encodeDocument.
+// go-ethereum has no packed encoder, so the rules Solidity applies are
+// reproduced here on top of geth's types and standard element encoding.
+func runPacked(data []byte) {
+	var doc encodeDocument
+	if err := unmarshalJSON(data, &doc); err != nil {
+		fatalf("Error parsing JSON document: %v", err)
+	}
+
+	encoded, err := encodePackedValues(doc.Types, doc.Values)
+	if err != nil {
+		fatalf("Packed encoding error: %v", err)
+	}
+
+	fmt.Printf("0x%x\n", encoded)
+}
+
+// encodePackedValues packs JSON values against parameter declarations using
+// the non-standard packed mode.
+func encodePackedValues(params []abi.ArgumentMarshaling, values []interface{}) ([]byte, error) {
+	args, err := newArguments(params)
+	if err != nil {
+		return nil, err
+	}
+	goVals, err := goValues(args, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	for i, arg := range args {
+		b, err := packedValue(arg.Type, reflect.ValueOf(goVals[i]))
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d (%s): %v", i, arg.Type.String(), err)
+		}
+		out = append(out, b...)
+	}
+	return out, nil
+}
+
+// packedValue encodes a top-level value: elementary types use their minimal
+// width, dynamic bytes and strings are written without a length, and array
+// elements are padded to 32 bytes each as in the standard encoding.
+func packedValue(t abi.Type, v reflect.Value) ([]byte, error) {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		return math.U256Bytes(bigIntOf(v))[32-t.Size/8:], nil
+
+	case abi.BoolTy:
+		if v.Bool() {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+
+	case abi.StringTy:
+		return []byte(v.String()), nil
+
+	case abi.BytesTy:
+		return v.Bytes(), nil
+
+	case abi.AddressTy, abi.FixedBytesTy, abi.FunctionTy:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return b, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		switch t.Elem.T {
+		case abi.SliceTy, abi.ArrayTy:
+			return nil, fmt.Errorf("nested arrays are not supported in packed mode")
+		case abi.TupleTy:
+			return nil, fmt.Errorf("arrays of structs are not supported in packed mode")
+		case abi.StringTy, abi.BytesTy:
+			return nil, fmt.Errorf("arrays of dynamic %s are not supported in packed mode", t.Elem.String())
+		}
+		elem := abi.Arguments{{Type: *t.Elem}}
+		var out []byte
+		for i := 0; i < v.Len(); i++ {
+			b, err := elem.Pack(v.Index(i).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", i, err)
+			}
+			out = append(out, b...)
+		}
+		return out, nil
+
+	case abi.TupleTy:
+		return nil, fmt.Errorf("structs are not supported in packed mode")
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t.String())
+}
+
+// bigIntOf returns the integer held by v, which is either one of Go's sized
+// integer kinds or a *big.Int.
+func bigIntOf(v reflect.Value) *big.Int {
+	switch v.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(v.Uint())
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int())
+	}
+	return new(big.Int).Set(v.Interface().(*big.Int))
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// pocoClass is a generated C# class: a struct of the ABI, or the inputs or
+// outputs of a function.
+type pocoClass struct {
+	Name       string
+	Summary    string
+	Shape      string
+	Properties []pocoProperty
+}
+
+type pocoProperty struct {
+	Attribute string
+	Type      string
+	Name      string
+	Init      string
+}
+
+// pocoGenerator collects the classes of one ABI file. Structs are shared
+// between every function that uses them.
+type pocoGenerator struct {
+	structs   []*pocoClass
+	functions []*pocoClass
+	byName    map[string]*pocoClass
+}
+
+var arraySuffix = regexp.MustCompile(`(\[\d*\])+$`)
+
+// runPocos prints C# classes for the structs and for the inputs and outputs
+// of the functions of an ABI file, for use with AbiConverter.
+func runPocos(abiPath, namespace string) {
+	if abiPath == "" {
+		fatalf("Error: No ABI file provided, use --abi")
+	}
+	entries, err := loadABIEntries(abiPath)
+	if err != nil {
+		fatalf("Error loading ABI %s: %v", abiPath, err)
+	}
+
+	g := &pocoGenerator{byName: make(map[string]*pocoClass)}
+	if err := g.functionClasses(entries); err != nil {
+		fatalf("Error generating classes: %v", err)
+	}
+	g.write(namespace)
+}
+
+// functionClasses adds an Inputs and an Outputs class for every function
+// that has parameters. Overloads are numbered as geth numbers them (foo,
+// foo0, foo1, ...).
+func (g *pocoGenerator) functionClasses(entries []abiEntry) error {
+	seen := make(map[string]int)
+	for _, e := range entries {
+		if e.Type != "function" {
+			continue
+		}
+		name := e.Name
+		if n, ok := seen[e.Name]; ok {
+			name = fmt.Sprintf("%s%d", e.Name, n-1)
+		}
+		seen[e.Name]++
+
+		args, err := newArguments(e.Inputs)
+		if err != nil {
+			return fmt.Errorf("%s: %v", e.Name, err)
+		}
+		types := make([]string, len(args))
+		for i, arg := range args {
+			types[i] = arg.Type.String()
+		}
+		sig := fmt.Sprintf("%s(%s)", e.Name, strings.Join(types, ","))
+
+		for _, side := range []struct {
+			suffix string
+			params []abi.ArgumentMarshaling
+		}{{"Inputs", e.Inputs}, {"Outputs", e.Outputs}} {
+			if len(side.params) == 0 {
+				continue
+			}
+			class := &pocoClass{
+				Name:    pascalCase(name) + side.suffix,
+				Summary: fmt.Sprintf("The %s of %s.", strings.ToLower(side.suffix), sig),
+			}
+			if err := g.addProperties(class, side.params); err != nil {
+				return fmt.Errorf("%s: %v", sig, err)
+			}
+			g.functions = append(g.functions, class)
+		}
+	}
+	return nil
+}
+
+// addProperties adds a property to class for each parameter, generating the
+// classes of any tuple parameters first.
+func (g *pocoGenerator) addProperties(class *pocoClass, params []abi.ArgumentMarshaling) error {
+	used := map[string]bool{class.Name: true}
+	for i, param := range params {
+		t, err := newType(param)
+		if err != nil {
+			return fmt.Errorf("%s: %v", param.Name, err)
+		}
+
+		name := pascalCase(param.Name)
+		if name == "" {
+			name = fmt.Sprintf("Item%d", i)
+		}
+		for used[name] {
+			name += fmt.Sprint(i)
+		}
+		used[name] = true
+
+		typ, init, err := g.propertyType(t, param, class.Name+name)
+		if err != nil {
+			return err
+		}
+
+		attribute := fmt.Sprintf("[AbiParameter(%s, Position = %d, AbiType = %s)]", csharpString(param.Name), i, csharpString(t.String()))
+		if strings.TrimSpace(param.Name) == "" {
+			attribute = fmt.Sprintf("[AbiParameter(%d, AbiType = %s)]", i, csharpString(t.String()))
+		}
+		class.Properties = append(class.Properties, pocoProperty{attribute, typ, name, init})
+	}
+	return nil
+}
+
+// propertyType returns the C# type of a parameter and its initialiser, if it
+// needs one. Tuples become classes named after their struct, or after where
+// they are used when the ABI has no internalType for them.
+func (g *pocoGenerator) propertyType(t abi.Type, param abi.ArgumentMarshaling, fallback string) (string, string, error) {
+	switch t.T {
+	case abi.SliceTy, abi.ArrayTy:
+		elem, _, err := g.propertyType(*t.Elem, param, fallback)
+		if err != nil {
+			return "", "", err
+		}
+		return elem + "[]", fmt.Sprintf("Array.Empty<%s>()", elem), nil
+	case abi.TupleTy:
+		class, err := g.structClass(t, param, fallback)
+		if err != nil {
+			return "", "", err
+		}
+		return class.Name, "new()", nil
+	case abi.StringTy:
+		return "string", `""`, nil
+	case abi.AddressTy:
+		return "EthereumAddress", "", nil
+	case abi.BytesTy, abi.FixedBytesTy, abi.FunctionTy:
+		return "Hex", "", nil
+	}
+	typ, err := csharpType(t)
+	return typ, "", err
+}
+
+// structClass returns the class of a tuple, generating it the first time the
+// struct is seen. Two different tuples with the same name are told apart by
+// qualifying the later one with its contract or a number.
+func (g *pocoGenerator) structClass(t abi.Type, param abi.ArgumentMarshaling, fallback string) (*pocoClass, error) {
+	name, qualified := fallback, fallback
+	if strings.HasPrefix(param.InternalType, "struct ") {
+		raw := arraySuffix.ReplaceAllString(strings.TrimPrefix(param.InternalType, "struct "), "")
+		qualified = pascalCase(strings.ReplaceAll(raw, ".", "_"))
+		name = pascalCase(raw[strings.LastIndex(raw, ".")+1:])
+	}
+
+	shape := t.String()
+	for _, candidate := range []string{name, qualified} {
+		if class, ok := g.byName[candidate]; ok && class.Shape == shape {
+			return class, nil
+		}
+	}
+	for n := 2; g.byName[name] != nil; n++ {
+		if g.byName[qualified] == nil {
+			name = qualified
+			break
+		}
+		name = fmt.Sprintf("%s%d", qualified, n)
+	}
+
+	class := &pocoClass{Name: name, Shape: shape}
+	if strings.HasPrefix(param.InternalType, "struct ") {
+		raw := arraySuffix.ReplaceAllString(strings.TrimPrefix(param.InternalType, "struct "), "")
+		class.Summary = fmt.Sprintf("The %s struct, ABI type %s.", raw, shape)
+	} else {
+		class.Summary = fmt.Sprintf("A tuple of ABI type %s.", shape)
+	}
+	g.byName[name] = class
+	g.structs = append(g.structs, class)
+	return class, g.addProperties(class, param.Components)
+}
+
+// write prints the classes, structs first and each group sorted by name.
+func (g *pocoGenerator) write(namespace string) {
+	sort.Slice(g.structs, func(i, j int) bool { return g.structs[i].Name < g.structs[j].Name })
+	sort.SliceStable(g.functions, func(i, j int) bool { return g.functions[i].Name < g.functions[j].Name })
+
+	var b strings.Builder
+	b.WriteString("// <auto-generated>\n// Generated by gabi (tests/go-abi-encoder) from an ABI JSON file. Do not edit by hand.\n// </auto-generated>\n\n")
+	b.WriteString("using System.Numerics;\nusing Evoq.Blockchain;\nusing Evoq.Ethereum;\nusing Evoq.Ethereum.ABI.Conversion;\n\n")
+	fmt.Fprintf(&b, "namespace %s;\n", namespace)
+
+	for _, class := range append(g.structs, g.functions...) {
+		fmt.Fprintf(&b, "\n/// <summary>\n/// %s\n/// </summary>\npublic class %s\n{\n", xmlEscape(class.Summary), class.Name)
+		for i, p := range class.Properties {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "    %s\n    public %s %s { get; set; }", p.Attribute, p.Type, p.Name)
+			if p.Init != "" {
+				fmt.Fprintf(&b, " = %s;", p.Init)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("}\n")
+	}
+
+	if _, err := os.Stdout.WriteString(b.String()); err != nil {
+		fatalf("Error writing classes: %v", err)
+	}
+}
+
+// pascalCase turns a Solidity identifier into a C# property or class name,
+// e.g. "_tokenURI" into "TokenURI" and "max_supply" into "MaxSupply".
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '$' }) {
+		r := []rune(part)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	out := b.String()
+	if out != "" && unicode.IsDigit([]rune(out)[0]) {
+		out = "_" + out
+	}
+	return out
+}
+
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
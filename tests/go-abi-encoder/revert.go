@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// decodedRevert is the JSON output of the revert mode. Kind is one of
+// "empty", "error" (Error(string)), "panic" (Panic(uint256)) or "custom".
+type decodedRevert struct {
+	Kind      string        `json:"kind"`
+	Name      string        `json:"name,omitempty"`
+	Signature string        `json:"signature,omitempty"`
+	Selector  string        `json:"selector,omitempty"`
+	Reason    string        `json:"reason,omitempty"`
+	Values    orderedObject `json:"values,omitempty"`
+}
+
+// runRevert identifies and decodes revert data, optionally resolving custom
+// errors against the errors of a contract ABI.
+func runRevert(abiPath, dataHex string) {
+	var contract *abi.ABI
+	if abiPath != "" {
+		c := mustLoadABI(abiPath)
+		contract = &c
+	}
+
+	data, err := hexutil.Decode(dataHex)
+	if err != nil {
+		fatalf("Error decoding hex data: %v", err)
+	}
+
+	decoded, err := decodeRevert(contract, data)
+	if err != nil {
+		fatalf("Decoding error: %v", err)
+	}
+
+	printJSON(decoded)
+}
+
+// decodeRevert decodes the return data of a reverted call. The built-in
+// Error(string) and Panic(uint256) are described by geth's UnpackRevert,
+// which names the panic code; anything else must be a custom error declared
+// in the ABI.
+func decodeRevert(contract *abi.ABI, data []byte) (decodedRevert, error) {
+	if len(data) == 0 {
+		return decodedRevert{Kind: "empty"}, nil
+	}
+	if len(data) < 4 {
+		return decodedRevert{}, fmt.Errorf("%d bytes of revert data is too short for a selector", len(data))
+	}
+	selector := hexutil.Encode(data[:4])
+
+	switch {
+	case bytes.Equal(data[:4], errorSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return decodedRevert{}, err
+		}
+		return decodedRevert{
+			Kind:      "error",
+			Name:      "Error",
+			Signature: "Error(string)",
+			Selector:  selector,
+			Reason:    reason,
+			Values:    orderedObject{{"message", reason}},
+		}, nil
+
+	case bytes.Equal(data[:4], panicSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return decodedRevert{}, err
+		}
+		// Read the code the way UnpackRevert does, from the first word only,
+		// so that trailing bytes cannot make it disagree with the reason.
+		uint256, _ := abi.NewType("uint256", "", nil)
+		unpacked, err := abi.Arguments{{Type: uint256}}.Unpack(data[4:])
+		if err != nil {
+			return decodedRevert{}, err
+		}
+		code := unpacked[0].(*big.Int)
+		return decodedRevert{
+			Kind:      "panic",
+			Name:      "Panic",
+			Signature: "Panic(uint256)",
+			Selector:  selector,
+			Reason:    reason,
+			Values:    orderedObject{{"code", json.Number(code.String())}},
+		}, nil
+	}
+
+	if contract == nil {
+		return decodedRevert{}, fmt.Errorf("unknown error selector %s; pass --abi to resolve custom errors", selector)
+	}
+
+	var id [4]byte
+	copy(id[:], data[:4])
+	e, err := contract.ErrorByID(id)
+	if err != nil {
+		return decodedRevert{}, fmt.Errorf("no error in ABI matches selector %s", selector)
+	}
+
+	unpacked, err := e.Inputs.Unpack(data[4:])
+	if err != nil {
+		return decodedRevert{}, fmt.Errorf("%s: %v", e.Sig, err)
+	}
+	values := make(orderedObject, len(e.Inputs))
+	for i, input := range e.Inputs {
+		values[i] = field{input.Name, jsonValue(input.Type, nil, reflect.ValueOf(unpacked[i]))}
+	}
+
+	return decodedRevert{
+		Kind:      "custom",
+		Name:      e.Name,
+		Signature: e.Sig,
+		Selector:  selector,
+		Values:    values,
+	}, nil
+}
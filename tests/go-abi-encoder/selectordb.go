@@ -0,0 +1,93 @@
+package main
+
+// selectorDatabase is the offline signature database the calldata mode uses
+// when no ABI is given: common ERC, EAS and DeFi functions, with parameter
+// names so that decoded arguments can be labelled. Signatures that
+// canonicalise to one already listed are skipped.
+var selectorDatabase = []string{
+	// ERC-20 and common extensions
+	"transfer(address to, uint256 amount)",
+	"transferFrom(address from, address to, uint256 amount)",
+	"approve(address spender, uint256 amount)",
+	"balanceOf(address account)",
+	"allowance(address owner, address spender)",
+	"totalSupply()",
+	"name()",
+	"symbol()",
+	"decimals()",
+	"increaseAllowance(address spender, uint256 addedValue)",
+	"decreaseAllowance(address spender, uint256 subtractedValue)",
+	"mint(address to, uint256 amount)",
+	"burn(uint256 amount)",
+	"burnFrom(address account, uint256 amount)",
+
+	// ERC-2612
+	"permit(address owner, address spender, uint256 value, uint256 deadline, uint8 v, bytes32 r, bytes32 s)",
+	"nonces(address owner)",
+	"DOMAIN_SEPARATOR()",
+
+	// ERC-721
+	"ownerOf(uint256 tokenId)",
+	"safeTransferFrom(address from, address to, uint256 tokenId)",
+	"safeTransferFrom(address from, address to, uint256 tokenId, bytes data)",
+	"setApprovalForAll(address operator, bool approved)",
+	"getApproved(uint256 tokenId)",
+	"isApprovedForAll(address owner, address operator)",
+	"tokenURI(uint256 tokenId)",
+
+	// ERC-1155
+	"safeTransferFrom(address from, address to, uint256 id, uint256 value, bytes data)",
+	"safeBatchTransferFrom(address from, address to, uint256[] ids, uint256[] values, bytes data)",
+	"balanceOf(address account, uint256 id)",
+	"balanceOfBatch(address[] accounts, uint256[] ids)",
+	"uri(uint256 id)",
+
+	// ERC-165 and Ownable
+	"supportsInterface(bytes4 interfaceId)",
+	"owner()",
+	"transferOwnership(address newOwner)",
+	"renounceOwnership()",
+
+	// WETH
+	"deposit()",
+	"withdraw(uint256 wad)",
+
+	// Multicall3
+	"aggregate((address target, bytes callData)[] calls)",
+	"tryAggregate(bool requireSuccess, (address target, bytes callData)[] calls)",
+	"aggregate3((address target, bool allowFailure, bytes callData)[] calls)",
+	"aggregate3Value((address target, bool allowFailure, uint256 value, bytes callData)[] calls)",
+
+	// Uniswap V2 router
+	"swapExactTokensForTokens(uint256 amountIn, uint256 amountOutMin, address[] path, address to, uint256 deadline)",
+	"swapTokensForExactTokens(uint256 amountOut, uint256 amountInMax, address[] path, address to, uint256 deadline)",
+	"swapExactETHForTokens(uint256 amountOutMin, address[] path, address to, uint256 deadline)",
+	"swapExactTokensForETH(uint256 amountIn, uint256 amountOutMin, address[] path, address to, uint256 deadline)",
+	"addLiquidity(address tokenA, address tokenB, uint256 amountADesired, uint256 amountBDesired, uint256 amountAMin, uint256 amountBMin, address to, uint256 deadline)",
+	"addLiquidityETH(address token, uint256 amountTokenDesired, uint256 amountTokenMin, uint256 amountETHMin, address to, uint256 deadline)",
+	"removeLiquidity(address tokenA, address tokenB, uint256 liquidity, uint256 amountAMin, uint256 amountBMin, address to, uint256 deadline)",
+
+	// Uniswap V3 router
+	"exactInputSingle((address tokenIn, address tokenOut, uint24 fee, address recipient, uint256 deadline, uint256 amountIn, uint256 amountOutMinimum, uint160 sqrtPriceLimitX96) params)",
+	"exactInput((bytes path, address recipient, uint256 deadline, uint256 amountIn, uint256 amountOutMinimum) params)",
+	"exactOutputSingle((address tokenIn, address tokenOut, uint24 fee, address recipient, uint256 deadline, uint256 amountOut, uint256 amountInMaximum, uint160 sqrtPriceLimitX96) params)",
+	"multicall(bytes[] data)",
+	"multicall(uint256 deadline, bytes[] data)",
+
+	// EAS and SchemaRegistry
+	"attest((bytes32 schema, (address recipient, uint64 expirationTime, bool revocable, bytes32 refUID, bytes data, uint256 value) data) request)",
+	"attestByDelegation((bytes32 schema, (address recipient, uint64 expirationTime, bool revocable, bytes32 refUID, bytes data, uint256 value) data, (uint8 v, bytes32 r, bytes32 s) signature, address attester, uint64 deadline) delegatedRequest)",
+	"multiAttest((bytes32 schema, (address recipient, uint64 expirationTime, bool revocable, bytes32 refUID, bytes data, uint256 value)[] data)[] multiRequests)",
+	"revoke((bytes32 schema, (bytes32 uid, uint256 value) data) request)",
+	"multiRevoke((bytes32 schema, (bytes32 uid, uint256 value)[] data)[] multiRequests)",
+	"getAttestation(bytes32 uid)",
+	"isAttestationValid(bytes32 uid)",
+	"timestamp(bytes32 data)",
+	"register(string schema, address resolver, bool revocable)",
+	"getSchema(bytes32 uid)",
+
+	// Known collisions with transfer(address,uint256), all 0xa9059cbb, kept
+	// so that ambiguous selectors are reported.
+	"transfer(bytes4[9] a, bytes5[6] b, int48[11] c)",
+	"func_2093253501(bytes a)",
+}
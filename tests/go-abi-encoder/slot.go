@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// slotDocument is the JSON input of the slot mode: the slot of a state
+// variable and a path of mapping keys, array indices and struct members
+// leading from it, for example balances[owner][spender]:
+//
+//	{"slot": 1, "path": [
+//	  {"keyType": "address", "key": "0x..."},
+//	  {"keyType": "address", "key": "0x..."}]}
+type slotDocument struct {
+	Slot interface{} `json:"slot"`
+	Path []slotStep  `json:"path"`
+}
+
+// slotStep is one step of a storage path, which is one of:
+//
+//   - a mapping lookup, with keyType and key;
+//   - a dynamic array element, with index and the size of an element, either
+//     elementSlots (default 1) or, for value types packed several to a slot,
+//     elementBytes;
+//   - a struct member or fixed array element, with member, the slot offset
+//     from the start of the struct, and offset, the byte offset within the
+//     slot, as solc's storageLayout output gives them.
+type slotStep struct {
+	KeyType      string      `json:"keyType"`
+	Key          interface{} `json:"key"`
+	Index        interface{} `json:"index"`
+	ElementSlots int         `json:"elementSlots"`
+	ElementBytes int         `json:"elementBytes"`
+	Member       interface{} `json:"member"`
+	Offset       int         `json:"offset"`
+}
+
+// slotResult is the JSON output of the slot mode. Offset is the byte offset
+// of a packed value from the low-order end of the slot.
+type slotResult struct {
+	Slot   string      `json:"slot"`
+	Offset int         `json:"offset"`
+	Steps  []slotTrace `json:"steps"`
+}
+
+type slotTrace struct {
+	Step string `json:"step"`
+	Slot string `json:"slot"`
+}
+
+// runSlot computes the storage slot a path leads to.
+func runSlot(data []byte) {
+	var doc slotDocument
+	if err := unmarshalJSON(data, &doc); err != nil {
+		fatalf("Error parsing JSON document: %v", err)
+	}
+
+	result, err := storageSlot(doc)
+	if err != nil {
+		fatalf("Error computing slot: %v", err)
+	}
+	printJSON(result)
+}
+
+// storageSlot follows a path with Solidity's storage layout rules:
+//
+//   - the value for key k of a mapping at slot p is at keccak256(h(k) . p),
+//     where h pads value types to 32 bytes as the ABI does and leaves
+//     string and bytes keys unpadded;
+//   - the elements of a dynamic array at slot p start at keccak256(p);
+//   - struct members and fixed array elements follow the slot they start at.
+func storageSlot(doc slotDocument) (slotResult, error) {
+	slot, err := toBigInt(doc.Slot)
+	if err != nil {
+		return slotResult{}, fmt.Errorf("slot: %v", err)
+	}
+	if slot.Sign() < 0 || slot.BitLen() > 256 {
+		return slotResult{}, fmt.Errorf("slot %s out of range", slot)
+	}
+
+	result := slotResult{Steps: []slotTrace{}}
+	packed := false
+	for i, step := range doc.Path {
+		if packed {
+			return slotResult{}, fmt.Errorf("path[%d]: a packed value has no further steps", i)
+		}
+
+		var desc string
+		switch {
+		case step.KeyType != "" && step.Index == nil && step.Member == nil:
+			key, err := mappingKey(step.KeyType, step.Key)
+			if err != nil {
+				return slotResult{}, fmt.Errorf("path[%d]: %v", i, err)
+			}
+			slot = new(big.Int).SetBytes(crypto.Keccak256(key, math.U256Bytes(slot)))
+			desc = fmt.Sprintf("mapping key %s %v", step.KeyType, step.Key)
+
+		case step.Index != nil && step.KeyType == "" && step.Member == nil:
+			index, err := toBigInt(step.Index)
+			if err != nil || index.Sign() < 0 {
+				return slotResult{}, fmt.Errorf("path[%d]: invalid index %v", i, step.Index)
+			}
+			start := new(big.Int).SetBytes(crypto.Keccak256(math.U256Bytes(slot)))
+			switch {
+			case step.ElementBytes != 0 && step.ElementSlots != 0:
+				return slotResult{}, fmt.Errorf("path[%d]: give either elementSlots or elementBytes", i)
+			case step.ElementBytes < 0 || step.ElementBytes > 32 || step.ElementSlots < 0:
+				return slotResult{}, fmt.Errorf("path[%d]: invalid element size", i)
+			case step.ElementBytes != 0:
+				perSlot := big.NewInt(int64(32 / step.ElementBytes))
+				q, r := new(big.Int).QuoRem(index, perSlot, new(big.Int))
+				slot = start.Add(start, q)
+				result.Offset = int(r.Int64()) * step.ElementBytes
+				packed = true
+			default:
+				size := step.ElementSlots
+				if size == 0 {
+					size = 1
+				}
+				slot = start.Add(start, new(big.Int).Mul(index, big.NewInt(int64(size))))
+			}
+			desc = fmt.Sprintf("array element %s", index)
+
+		case step.Member != nil && step.KeyType == "" && step.Index == nil:
+			member, err := toBigInt(step.Member)
+			if err != nil || member.Sign() < 0 {
+				return slotResult{}, fmt.Errorf("path[%d]: invalid member slot %v", i, step.Member)
+			}
+			if step.Offset < 0 || step.Offset > 31 {
+				return slotResult{}, fmt.Errorf("path[%d]: offset %d out of range", i, step.Offset)
+			}
+			slot = new(big.Int).Add(slot, member)
+			result.Offset = step.Offset
+			packed = step.Offset != 0
+			desc = fmt.Sprintf("member at slot +%s", member)
+
+		default:
+			return slotResult{}, fmt.Errorf("path[%d]: give exactly one of keyType, index or member", i)
+		}
+
+		slot.And(slot, math.MaxBig256)
+		result.Steps = append(result.Steps, slotTrace{desc, common.BigToHash(slot).Hex()})
+	}
+
+	result.Slot = common.BigToHash(slot).Hex()
+	return result, nil
+}
+
+// mappingKey returns the bytes a mapping key is hashed as: the 32-byte ABI
+// encoding of a value type, or the raw bytes of a string or bytes key.
+func mappingKey(typ string, v interface{}) ([]byte, error) {
+	param, err := parseParam(typ)
+	if err != nil {
+		return nil, err
+	}
+	t, err := newType(param)
+	if err != nil {
+		return nil, err
+	}
+	switch t.T {
+	case abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return nil, fmt.Errorf("%s cannot be a mapping key", t.String())
+	}
+
+	value, err := goValue(t, v, "key")
+	if err != nil {
+		return nil, err
+	}
+	switch t.T {
+	case abi.StringTy:
+		return []byte(value.String()), nil
+	case abi.BytesTy:
+		return value.Bytes(), nil
+	}
+	return abi.Arguments{{Type: t}}.Pack(value.Interface())
+}
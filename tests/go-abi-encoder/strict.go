@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// strictResult reports how geth's Unpack reacts to a payload. ErrorCategory
+// is a stable, machine-readable classification of Error.
+type strictResult struct {
+	Name          string        `json:"name,omitempty"`
+	Malformation  string        `json:"malformation,omitempty"`
+	Types         typeList      `json:"types"`
+	Data          string        `json:"data"`
+	Accepted      bool          `json:"accepted"`
+	Values        []interface{} `json:"values,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	ErrorCategory string        `json:"errorCategory,omitempty"`
+}
+
+// strictVector is a deliberately malformed (or, for contrast, well-formed)
+// payload.
+type strictVector struct {
+	name         string
+	malformation string
+	types        string
+	data         []byte
+}
+
+// runStrict classifies the payload of a decodeDocument, or when no document is
+// given, prints the classification of every built-in malformed vector.
+func runStrict(data []byte) {
+	if data != nil {
+		var doc decodeDocument
+		if err := unmarshalJSON(data, &doc); err != nil {
+			fatalf("Error parsing JSON document: %v", err)
+		}
+		payload, err := hexutil.Decode(doc.Data)
+		if err != nil {
+			fatalf("Error decoding hex data: %v", err)
+		}
+		printJSON(classify(doc.Types, payload))
+		return
+	}
+
+	var results []strictResult
+	for _, v := range strictVectors() {
+		params, err := parseTypeList(v.types)
+		if err != nil {
+			fatalf("Error parsing %s: %v", v.types, err)
+		}
+		r := classify(params, v.data)
+		r.Name, r.Malformation = v.name, v.malformation
+		results = append(results, r)
+	}
+	printJSON(results)
+}
+
+// classify unpacks payload and records whether geth accepted it.
+func classify(params typeList, payload []byte) strictResult {
+	r := strictResult{Types: params, Data: hexutil.Encode(payload)}
+	values, err := decodeValues(params, payload)
+	if err != nil {
+		r.Error = err.Error()
+		r.ErrorCategory = errorCategory(err.Error())
+		return r
+	}
+	r.Accepted = true
+	r.Values = values
+	return r
+}
+
+// errorCategory maps geth's unpacking errors onto stable categories.
+func errorCategory(msg string) string {
+	switch {
+	case strings.Contains(msg, "improperly encoded boolean"):
+		return "invalid-bool"
+	case strings.Contains(msg, "improperly encoded u"), strings.Contains(msg, "improperly encoded int"):
+		return "integer-out-of-range"
+	case strings.Contains(msg, "improperly encoded function"):
+		return "invalid-function"
+	case strings.Contains(msg, "empty string while arguments are expected"):
+		return "empty-payload"
+	case strings.Contains(msg, "length insufficient"):
+		return "truncated"
+	case strings.Contains(msg, "would go over slice boundary"), strings.Contains(msg, "offset greater than output length"):
+		return "out-of-bounds"
+	case strings.Contains(msg, "length larger than int64"):
+		return "length-overflow"
+	case strings.Contains(msg, "offset larger than int64"):
+		return "offset-overflow"
+	case strings.Contains(msg, "size is negative"):
+		return "negative-size"
+	}
+	return "other"
+}
+
+// word returns n as a 32-byte big-endian word (two's complement if negative).
+func word(n int64) []byte {
+	return math.U256Bytes(big.NewInt(n))
+}
+
+// hexWord returns a 32-byte word from hex, left-padded with zeros.
+func hexWord(h string) []byte {
+	b := hexutil.MustDecode(h)
+	return append(make([]byte, 32-len(b)), b...)
+}
+
+// rightPadded returns b right-padded with zeros to a multiple of 32 bytes.
+func rightPadded(b []byte) []byte {
+	if rem := len(b) % 32; rem != 0 {
+		return append(b, make([]byte, 32-rem)...)
+	}
+	return b
+}
+
+func join(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// strictVectors returns the built-in vectors. Well-formed controls are
+// included next to the malformations they contrast with.
+func strictVectors() []strictVector {
+	abc := rightPadded([]byte("abc"))
+	ones := bytes.Repeat([]byte{0xff}, 32)
+
+	return []strictVector{
+		{"uint8 max", "none", "uint8", word(255)},
+		{"uint8 dirty high-order padding", "dirty-padding", "uint8", word(0x0101)},
+		{"uint16 dirty high-order padding", "dirty-padding", "uint16", word(0x010001)},
+		{"uint24 dirty high-order padding", "dirty-padding", "uint24", word(0x01000001)},
+		{"uint32 dirty high-order padding", "dirty-padding", "uint32", hexWord("0x0100000001")},
+		{"uint64 dirty high-order padding", "dirty-padding", "uint64", hexWord("0x010000000000000001")},
+		{"uint128 dirty high-order padding", "dirty-padding", "uint128", hexWord("0x0100000000000000000000000000000001")},
+		{"int8 min", "none", "int8", word(-128)},
+		{"int8 negative without sign extension", "dirty-padding", "int8", hexWord("0x80")},
+		{"int8 sign extension below min", "dirty-padding", "int8", word(-129)},
+		{"int32 positive with high bits set", "dirty-padding", "int32", hexWord("0x0100000000")},
+		{"int24 negative without sign extension", "dirty-padding", "int24", hexWord("0x800000")},
+		{"bool true", "none", "bool", word(1)},
+		{"bool value 2", "invalid-bool", "bool", word(2)},
+		{"bool all ones", "invalid-bool", "bool", ones},
+		{"bool dirty high-order byte", "invalid-bool", "bool", join([]byte{0x01}, make([]byte, 30), []byte{0x01})},
+		{"address dirty high-order padding", "dirty-padding", "address", join(bytes.Repeat([]byte{0xaa}, 12), bytes.Repeat([]byte{0x11}, 20))},
+		{"bytes4 dirty low-order padding", "dirty-padding", "bytes4", join([]byte{1, 2, 3, 4}, bytes.Repeat([]byte{0xbb}, 28))},
+		{"bytes1 dirty low-order padding", "dirty-padding", "bytes1", ones},
+		{"empty payload", "truncated", "uint256", []byte{}},
+		{"payload shorter than head", "truncated", "uint256", make([]byte, 16)},
+		{"missing second head word", "truncated", "uint256, uint256", word(1)},
+		{"trailing bytes after payload", "trailing-data", "uint256", join(word(1), word(2))},
+		{"payload not a multiple of 32 bytes", "trailing-data", "uint256", join(word(1), []byte{0xff})},
+		{"string", "none", "string", join(word(0x20), word(3), abc)},
+		{"string offset out of bounds", "bad-offset", "string", join(word(0x1000), word(3), abc)},
+		{"string offset near 2^256", "bad-offset", "string", join(ones, word(3), abc)},
+		{"string offset not word aligned", "bad-offset", "string", join(word(0x21), word(3), abc)},
+		{"string offset pointing into head", "bad-offset", "string", join(word(0), word(3), abc)},
+		{"string length beyond payload", "truncated", "string", join(word(0x20), word(100), abc)},
+		{"string length near 2^256", "truncated", "string", join(word(0x20), ones, abc)},
+		{"string data cut short", "truncated", "string", join(word(0x20), word(3), []byte("ab"))},
+		{"string missing padding", "truncated", "string", join(word(0x20), word(3), []byte("abc"))},
+		{"string invalid UTF-8", "invalid-utf8", "string", join(word(0x20), word(2), rightPadded([]byte{0xc3, 0x28}))},
+		{"string dirty padding after data", "dirty-padding", "string", join(word(0x20), word(3), []byte("abc"), bytes.Repeat([]byte{0xee}, 29))},
+		{"overlapping dynamic data", "overlap", "string, string", join(word(0x40), word(0x40), word(3), abc)},
+		{"dynamic data inside head", "overlap", "uint256, string", join(word(0x20), word(0x20))},
+		{"bytes offset pointing past the length word", "bad-offset", "bytes", join(word(0x40), word(3), abc)},
+		{"uint256[] length beyond payload", "truncated", "uint256[]", join(word(0x20), word(1000), word(1))},
+		{"uint256[] length near 2^64", "truncated", "uint256[]", join(word(0x20), hexWord("0xffffffffffffffff"), word(1))},
+		{"uint8[] element with dirty padding", "dirty-padding", "uint8[]", join(word(0x20), word(1), word(0x100))},
+		{"bool[2] element not 0 or 1", "invalid-bool", "bool[2]", join(word(1), word(7))},
+		{"string[2] element offset out of bounds", "bad-offset", "string[2]", join(word(0x20), word(0x40), word(0x1000), word(3), abc)},
+		{"string[2] array offset out of bounds", "bad-offset", "string[2]", join(word(0x1000), word(0x40), word(0x80))},
+		{"(string) tuple offset out of bounds", "bad-offset", "(string a)", join(word(0x1000), word(0x20), word(3), abc)},
+		{"(uint8,bool) tuple with dirty uint8", "dirty-padding", "(uint8 a, bool b)", join(word(0x1ff), word(1))},
+	}
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// typeList is the JSON form of a list of ABI parameters. It accepts either a
+// single Solidity-style string such as "uint256 id, (bool ok, string name)[] items"
+// or an array whose entries are type strings or ABI JSON parameter objects
+// ({"name": ..., "type": ..., "components": [...]}).
+type typeList []abi.ArgumentMarshaling
+
+func (l *typeList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		params, err := parseTypeList(s)
+		if err != nil {
+			return err
+		}
+		*l = params
+		return nil
+	}
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("types must be a string or an array: %v", err)
+	}
+
+	params := make([]abi.ArgumentMarshaling, 0, len(entries))
+	for i, entry := range entries {
+		if err := json.Unmarshal(entry, &s); err == nil {
+			p, err := parseParam(s)
+			if err != nil {
+				return fmt.Errorf("types[%d]: %v", i, err)
+			}
+			params = append(params, p)
+			continue
+		}
+
+		var p abi.ArgumentMarshaling
+		if err := json.Unmarshal(entry, &p); err != nil {
+			return fmt.Errorf("types[%d]: %v", i, err)
+		}
+		params = append(params, p)
+	}
+	*l = params
+	return nil
+}
+
+// MarshalJSON writes the list in the Solidity-style string form.
+func (l typeList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(formatParams(l))
+}
+
+// parseTypeList parses a comma separated list of Solidity parameter
+// declarations, e.g. "uint8 a, (uint256 id, string name)[] users".
+func parseTypeList(s string) ([]abi.ArgumentMarshaling, error) {
+	p := &typeParser{src: s}
+	params, err := p.params()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return params, nil
+}
+
+// parseParam parses a single parameter declaration such as "uint256[2] values".
+func parseParam(s string) (abi.ArgumentMarshaling, error) {
+	params, err := parseTypeList(s)
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+	if len(params) != 1 {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("expected a single type in %q", s)
+	}
+	return params[0], nil
+}
+
+// typeParser is a small recursive descent parser for Solidity style type
+// declarations. Tuples are written as parenthesised component lists and may
+// be prefixed with the "tuple" keyword.
+type typeParser struct {
+	src string
+	pos int
+}
+
+func (p *typeParser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *typeParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *typeParser) skipSpace() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\n' || p.peek() == '\r') {
+		p.pos++
+	}
+}
+
+func (p *typeParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("parsing %q at offset %d: %s", p.src, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *typeParser) identifier() string {
+	start := p.pos
+	for !p.done() {
+		c := p.peek()
+		if c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+// params parses zero or more comma separated parameters, stopping at the end
+// of input or at a closing parenthesis.
+func (p *typeParser) params() ([]abi.ArgumentMarshaling, error) {
+	params := []abi.ArgumentMarshaling{}
+	p.skipSpace()
+	if p.done() || p.peek() == ')' {
+		return params, nil
+	}
+	for {
+		param, err := p.param()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+
+		p.skipSpace()
+		if p.peek() != ',' {
+			return params, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *typeParser) param() (abi.ArgumentMarshaling, error) {
+	param, err := p.typ()
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+
+	p.skipSpace()
+	name := p.identifier()
+	if name == "indexed" {
+		param.Indexed = true
+		p.skipSpace()
+		name = p.identifier()
+	}
+	if name == "memory" || name == "calldata" || name == "storage" {
+		p.skipSpace()
+		name = p.identifier()
+	}
+	param.Name = name
+	return param, nil
+}
+
+func (p *typeParser) typ() (abi.ArgumentMarshaling, error) {
+	p.skipSpace()
+
+	var param abi.ArgumentMarshaling
+	if strings.HasPrefix(p.src[p.pos:], "tuple(") {
+		p.pos += len("tuple")
+	}
+
+	if p.peek() == '(' {
+		components, err := p.parenthesised()
+		if err != nil {
+			return param, err
+		}
+		param.Type = "tuple"
+		param.Components = components
+	} else {
+		elementary := p.identifier()
+		if elementary == "" {
+			return param, p.errorf("expected a type")
+		}
+		param.Type = canonicalElementary(elementary)
+	}
+
+	for p.peek() == '[' {
+		end := strings.IndexByte(p.src[p.pos:], ']')
+		if end < 0 {
+			return param, p.errorf("unterminated array suffix")
+		}
+		size := p.src[p.pos+1 : p.pos+end]
+		for _, c := range size {
+			if c < '0' || c > '9' {
+				return param, p.errorf("invalid array size %q", size)
+			}
+		}
+		param.Type += p.src[p.pos : p.pos+end+1]
+		p.pos += end + 1
+	}
+	return param, nil
+}
+
+// canonicalElementary expands the Solidity aliases that are not valid in a
+// canonical ABI signature.
+func canonicalElementary(t string) string {
+	switch t {
+	case "uint":
+		return "uint256"
+	case "int":
+		return "int256"
+	case "byte":
+		return "bytes1"
+	}
+	return t
+}
+
+// formatParams is the inverse of parseTypeList, writing tuples in their
+// parenthesised form, e.g. "uint8 a, (uint256 id, string name)[] users".
+func formatParams(params []abi.ArgumentMarshaling) string {
+	parts := make([]string, len(params))
+	for i, p := range params {
+		parts[i] = formatParam(p)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatParam(p abi.ArgumentMarshaling) string {
+	s := p.Type
+	if strings.HasPrefix(p.Type, "tuple") {
+		s = "(" + formatParams(p.Components) + ")" + strings.TrimPrefix(p.Type, "tuple")
+	}
+	if p.Indexed {
+		s += " indexed"
+	}
+	if p.Name != "" {
+		s += " " + p.Name
+	}
+	return s
+}
+
+// newArguments builds geth ABI arguments from parameter declarations.
+func newArguments(params []abi.ArgumentMarshaling) (abi.Arguments, error) {
+	args := make(abi.Arguments, 0, len(params))
+	for i, param := range params {
+		t, err := newType(param)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d (%s): %v", i, param.Type, err)
+		}
+		args = append(args, abi.Argument{Name: param.Name, Type: t, Indexed: param.Indexed})
+	}
+	return args, nil
+}
+
+// newType builds a geth ABI type. geth refuses tuple components without a
+// name, so unnamed components are given the same placeholder names geth's own
+// selector parser uses (name0, name1, ...).
+func newType(param abi.ArgumentMarshaling) (abi.Type, error) {
+	return abi.NewType(param.Type, param.InternalType, nameComponents(param.Components))
+}
+
+func nameComponents(components []abi.ArgumentMarshaling) []abi.ArgumentMarshaling {
+	if components == nil {
+		return nil
+	}
+	named := make([]abi.ArgumentMarshaling, len(components))
+	for i, c := range components {
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("name%d", i)
+		}
+		c.Components = nameComponents(c.Components)
+		named[i] = c
+	}
+	return named
+}
+
+// signature is a parsed human-readable function, event or error declaration.
+type signature struct {
+	Name      string
+	Inputs    []abi.ArgumentMarshaling
+	Outputs   []abi.ArgumentMarshaling
+	Anonymous bool
+}
+
+// parseSignature parses declarations such as "transfer(address,uint256)" or
+// "function balanceOf(address owner) external view returns (uint256)". A
+// leading "function", "event" or "error" keyword and any visibility or
+// mutability modifiers are ignored. Parameters may be marked "indexed" and
+// events may be declared "anonymous".
+func parseSignature(s string) (signature, error) {
+	p := &typeParser{src: s}
+	p.skipSpace()
+
+	name := p.identifier()
+	if name == "function" || name == "event" || name == "error" {
+		p.skipSpace()
+		name = p.identifier()
+	}
+	if name == "" {
+		return signature{}, p.errorf("expected a name")
+	}
+
+	inputs, err := p.parenthesised()
+	if err != nil {
+		return signature{}, err
+	}
+	sig := signature{Name: name, Inputs: inputs}
+
+	for {
+		p.skipSpace()
+		if p.done() {
+			return sig, nil
+		}
+		switch word := p.identifier(); word {
+		case "external", "public", "internal", "private", "view", "pure", "payable", "nonpayable":
+		case "anonymous":
+			sig.Anonymous = true
+		case "returns":
+			if sig.Outputs, err = p.parenthesised(); err != nil {
+				return signature{}, err
+			}
+		default:
+			return signature{}, p.errorf("unexpected %q", p.src[p.pos-len(word):])
+		}
+	}
+}
+
+// parenthesised parses a parameter list enclosed in parentheses.
+func (p *typeParser) parenthesised() ([]abi.ArgumentMarshaling, error) {
+	p.skipSpace()
+	if p.peek() != '(' {
+		return nil, p.errorf("expected '('")
+	}
+	p.pos++
+	params, err := p.params()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ')' {
+		return nil, p.errorf("expected ')'")
+	}
+	p.pos++
+	return params, nil
+}
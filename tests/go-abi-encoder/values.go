@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// goValues converts decoded JSON values into the Go values geth expects when
+// packing the given arguments.
+func goValues(args abi.Arguments, values []interface{}) ([]interface{}, error) {
+	if len(values) != len(args) {
+		return nil, fmt.Errorf("expected %d values, got %d", len(args), len(values))
+	}
+	out := make([]interface{}, len(values))
+	for i, arg := range args {
+		path := arg.Name
+		if path == "" {
+			path = fmt.Sprintf("[%d]", i)
+		}
+		v, err := goValue(arg.Type, values[i], path)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v.Interface()
+	}
+	return out, nil
+}
+
+// goValue converts a single JSON value into a reflect.Value of the Go type
+// geth uses for t. Integers may be JSON numbers or decimal/0x-hex strings;
+// bytes may be 0x-hex or plain text; tuples may be objects keyed by component
+// name or positional arrays.
+func goValue(t abi.Type, v interface{}, path string) (reflect.Value, error) {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := toBigInt(v)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %v", path, err)
+		}
+		if err := checkIntRange(t, n); err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %v", path, err)
+		}
+		rv := reflect.New(t.GetType()).Elem()
+		switch rv.Kind() {
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			rv.SetUint(n.Uint64())
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			rv.SetInt(n.Int64())
+		default:
+			rv.Set(reflect.ValueOf(n))
+		}
+		return rv, nil
+
+	case abi.BoolTy:
+		b, ok := v.(bool)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%s: expected a bool, got %T", path, v)
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.StringTy:
+		s, ok := v.(string)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%s: expected a string, got %T", path, v)
+		}
+		return reflect.ValueOf(s), nil
+
+	case abi.AddressTy:
+		s, ok := v.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("%s: expected a hex address, got %v", path, v)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+
+	case abi.BytesTy:
+		b, err := toBytes(v)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %v", path, err)
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.FixedBytesTy, abi.FunctionTy:
+		b, err := toBytes(v)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %v", path, err)
+		}
+		if len(b) > t.Size {
+			return reflect.Value{}, fmt.Errorf("%s: %d bytes do not fit in %s", path, len(b), t.String())
+		}
+		rv := reflect.New(t.GetType()).Elem()
+		reflect.Copy(rv, reflect.ValueOf(b))
+		return rv, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		items, ok := v.([]interface{})
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%s: expected an array, got %T", path, v)
+		}
+		var rv reflect.Value
+		if t.T == abi.ArrayTy {
+			if len(items) != t.Size {
+				return reflect.Value{}, fmt.Errorf("%s: expected %d elements, got %d", path, t.Size, len(items))
+			}
+			rv = reflect.New(t.GetType()).Elem()
+		} else {
+			rv = reflect.MakeSlice(t.GetType(), len(items), len(items))
+		}
+		for i, item := range items {
+			ev, err := goValue(*t.Elem, item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			rv.Index(i).Set(ev)
+		}
+		return rv, nil
+
+	case abi.TupleTy:
+		// geth packs a tuple by looking up each component's struct field by
+		// its camel-cased name, so components sharing a name would all read
+		// the first one's field.
+		fieldOf := make(map[string]string, len(t.TupleRawNames))
+		for _, name := range t.TupleRawNames {
+			other, ok := fieldOf[abi.ToCamelCase(name)]
+			switch {
+			case ok && other == name:
+				return reflect.Value{}, fmt.Errorf("%s: tuple component %q is declared twice; geth cannot pack it", path, name)
+			case ok:
+				return reflect.Value{}, fmt.Errorf("%s: tuple components %q and %q map to the same Go field; geth cannot pack them", path, other, name)
+			}
+			fieldOf[abi.ToCamelCase(name)] = name
+		}
+		rv := reflect.New(t.TupleType).Elem()
+		switch fields := v.(type) {
+		case map[string]interface{}:
+			if len(fields) != len(t.TupleElems) {
+				return reflect.Value{}, fmt.Errorf("%s: expected %d fields, got %d", path, len(t.TupleElems), len(fields))
+			}
+			for i, elem := range t.TupleElems {
+				name := t.TupleRawNames[i]
+				fv, ok := fields[name]
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("%s: missing field %q", path, name)
+				}
+				ev, err := goValue(*elem, fv, path+"."+name)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				rv.Field(i).Set(ev)
+			}
+		case []interface{}:
+			if len(fields) != len(t.TupleElems) {
+				return reflect.Value{}, fmt.Errorf("%s: expected %d fields, got %d", path, len(t.TupleElems), len(fields))
+			}
+			for i, elem := range t.TupleElems {
+				ev, err := goValue(*elem, fields[i], path+"."+t.TupleRawNames[i])
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				rv.Field(i).Set(ev)
+			}
+		default:
+			return reflect.Value{}, fmt.Errorf("%s: expected an object or array for %s, got %T", path, t.String(), v)
+		}
+		return rv, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("%s: unsupported type %s", path, t.String())
+}
+
+// toBigInt accepts JSON numbers and decimal or 0x-prefixed hex strings.
+func toBigInt(v interface{}) (*big.Int, error) {
+	var s string
+	switch n := v.(type) {
+	case json.Number:
+		s = n.String()
+	case string:
+		s = n
+	default:
+		return nil, fmt.Errorf("expected an integer, got %T", v)
+	}
+
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+	base := 10
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		digits, base = digits[2:], 16
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s)
+	}
+	if neg {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// checkIntRange rejects values that do not fit in the declared bit width.
+func checkIntRange(t abi.Type, n *big.Int) error {
+	min, max := intBounds(t)
+	if n.Cmp(min) < 0 || n.Cmp(max) > 0 {
+		return fmt.Errorf("%s out of range for %s", n, t.String())
+	}
+	return nil
+}
+
+// intBounds returns the smallest and largest values representable by an
+// int<N> or uint<N> type.
+func intBounds(t abi.Type) (*big.Int, *big.Int) {
+	if t.T == abi.UintTy {
+		max := new(big.Int).Lsh(big.NewInt(1), uint(t.Size))
+		return big.NewInt(0), max.Sub(max, big.NewInt(1))
+	}
+	max := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+	min := new(big.Int).Neg(max)
+	return min, max.Sub(max, big.NewInt(1))
+}
+
+// toBytes decodes 0x-prefixed hex, falling back to the UTF-8 bytes of the
+// string so that values such as "dave" can be written naturally.
+func toBytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a hex or text string, got %T", v)
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		b, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex %q: %v", s, err)
+		}
+		return b, nil
+	}
+	return []byte(s), nil
+}
+
+// field is a single member of an orderedObject.
+type field struct {
+	Name  string
+	Value interface{}
+}
+
+// orderedObject marshals to a JSON object whose keys keep the order of the
+// ABI components, which a Go map would not.
+type orderedObject []field
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonValues converts values unpacked by geth back into JSON values, using
+// the same conventions goValue accepts.
+func jsonValues(params []abi.ArgumentMarshaling, args abi.Arguments, values []interface{}) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = jsonValue(args[i].Type, params[i].Components, reflect.ValueOf(v))
+	}
+	return out
+}
+
+// jsonValue converts a single unpacked value. Integers become JSON numbers of
+// arbitrary size, byte strings become 0x-hex and addresses are checksummed.
+// Tuples become objects keyed by component name, or positional arrays when
+// any component is unnamed or names repeat. components are the declared tuple components of t
+// (or of its element type), which keep the original, possibly empty, names;
+// when nil, the names geth holds for the tuple are used.
+func jsonValue(t abi.Type, components []abi.ArgumentMarshaling, v reflect.Value) interface{} {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		switch v.Kind() {
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return json.Number(fmt.Sprint(v.Uint()))
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return json.Number(fmt.Sprint(v.Int()))
+		}
+		return json.Number(v.Interface().(*big.Int).String())
+
+	case abi.BoolTy, abi.StringTy:
+		return v.Interface()
+
+	case abi.AddressTy:
+		return v.Interface().(common.Address).Hex()
+
+	case abi.BytesTy:
+		return hexutil.Encode(v.Bytes())
+
+	case abi.FixedBytesTy, abi.FunctionTy:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return hexutil.Encode(b)
+
+	case abi.SliceTy, abi.ArrayTy:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = jsonValue(*t.Elem, components, v.Index(i))
+		}
+		return items
+
+	case abi.TupleTy:
+		named := components == nil || len(components) == len(t.TupleElems)
+		for _, c := range components {
+			named = named && c.Name != ""
+		}
+		// Repeated names would give an object with repeated keys, of which
+		// most JSON readers keep only the last.
+		seen := make(map[string]bool, len(t.TupleRawNames))
+		for _, name := range t.TupleRawNames {
+			named = named && !seen[name]
+			seen[name] = true
+		}
+		obj := make(orderedObject, len(t.TupleElems))
+		items := make([]interface{}, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			var sub []abi.ArgumentMarshaling
+			if i < len(components) {
+				sub = components[i].Components
+			}
+			items[i] = jsonValue(*elem, sub, v.Field(i))
+			obj[i] = field{t.TupleRawNames[i], items[i]}
+		}
+		if named {
+			return obj
+		}
+		return items
+	}
+
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// runWidths prints a corpus covering every intN, uintN and bytesN width and
+// address, each with its boundary values.
+func runWidths() {
+	var c corpus
+	add := func(name, typ string, value interface{}) {
+		params, err := parseTypeList(typ)
+		if err != nil {
+			fatalf("Error parsing %s: %v", typ, err)
+		}
+		encoded, err := encodeValues(params, []interface{}{value})
+		if err != nil {
+			fatalf("Error encoding %s %s: %v", typ, name, err)
+		}
+		c.Cases = append(c.Cases, corpusCase{
+			Name:      fmt.Sprintf("%s %s", typ, name),
+			Signature: fmt.Sprintf("foo(%s)", typ),
+			Types:     params,
+			Values:    []interface{}{value},
+			Encoded:   hexutil.Encode(encoded),
+		})
+	}
+
+	for bits := 8; bits <= 256; bits += 8 {
+		typ := fmt.Sprintf("uint%d", bits)
+		_, max := intBounds(abi.Type{T: abi.UintTy, Size: bits})
+		add("zero", typ, json.Number("0"))
+		add("one", typ, json.Number("1"))
+		add("max", typ, json.Number(max.String()))
+	}
+
+	for bits := 8; bits <= 256; bits += 8 {
+		// Negative values are sign-extended to 32 bytes with 0xff.
+		typ := fmt.Sprintf("int%d", bits)
+		min, max := intBounds(abi.Type{T: abi.IntTy, Size: bits})
+		add("min", typ, json.Number(min.String()))
+		add("min plus one", typ, json.Number(new(big.Int).Add(min, big.NewInt(1)).String()))
+		add("minus one", typ, json.Number("-1"))
+		add("zero", typ, json.Number("0"))
+		add("one", typ, json.Number("1"))
+		add("max", typ, json.Number(max.String()))
+	}
+
+	for size := 1; size <= 32; size++ {
+		// bytesN values are left-aligned and right-padded with zeros.
+		typ := fmt.Sprintf("bytes%d", size)
+		add("zero", typ, hexutil.Encode(make([]byte, size)))
+		add("leading one", typ, hexutil.Encode(append([]byte{0x01}, make([]byte, size-1)...)))
+		add("trailing one", typ, hexutil.Encode(append(make([]byte, size-1), 0x01)))
+		add("all ones", typ, hexutil.Encode(bytes.Repeat([]byte{0xff}, size)))
+	}
+
+	add("zero", "address", common.Address{}.Hex())
+	add("one", "address", common.BigToAddress(big.NewInt(1)).Hex())
+	add("max", "address", common.BytesToAddress(bytes.Repeat([]byte{0xff}, common.AddressLength)).Hex())
+	add("mixed case", "address", common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed").Hex())
+
+	printJSON(c)
+}
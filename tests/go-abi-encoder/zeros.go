@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// zeroCompositeTypes are the composite types the zeros mode covers besides
+// the elementary ones: empty dynamic arrays, fixed arrays and tuples of zero
+// values, static and dynamic, and nestings of them.
+var zeroCompositeTypes = []string{
+	"uint256[]",
+	"bool[]",
+	"address[]",
+	"bytes32[]",
+	"bytes[]",
+	"string[]",
+	"uint8[][]",
+	"uint256[][3]",
+	"uint256[1]",
+	"uint256[3]",
+	"bool[2][2]",
+	"address[2]",
+	"bytes[2]",
+	"string[2]",
+	"string[][2]",
+	"(uint256 a)",
+	"(uint256 a, address b, bool c, bytes32 d)",
+	"(int8 a, uint64 b, bytes4 c)",
+	"(string a, bytes b, uint256[] c)",
+	"((uint8 a, (bool b, address c) d) e, string[] f)",
+	"(uint256 a, bool b)[]",
+	"(uint256 a, bool b)[2]",
+	"(string a, uint256 b)[2]",
+	"(uint256[2] a, (bytes32 b)[1] c)",
+}
+
+// runZeros prints a corpus with the zero value of every elementary type and
+// of a set of composite types, as a reference for the default handling of
+// DefaultValueChecker and AbiConverter. Each encoding is checked to decode
+// back to the same zero value, and the encodings of static types to be all
+// zero bytes.
+func runZeros() {
+	var c corpus
+	add := func(typ string) {
+		params, err := parseTypeList(typ)
+		if err != nil {
+			fatalf("Error parsing %s: %v", typ, err)
+		}
+		args, err := newArguments(params)
+		if err != nil {
+			fatalf("Error parsing %s: %v", typ, err)
+		}
+
+		// Round-trip through JSON, as the fuzz mode does, so the values are
+		// exactly what a reader of the corpus will see.
+		data, err := json.Marshal([]interface{}{zeroValue(args[0].Type)})
+		if err != nil {
+			fatalf("Error encoding %s: %v", typ, err)
+		}
+		var values []interface{}
+		if err := unmarshalJSON(data, &values); err != nil {
+			fatalf("Error encoding %s: %v", typ, err)
+		}
+
+		encoded, err := encodeValues(params, values)
+		if err != nil {
+			fatalf("Error encoding %s: %v", typ, err)
+		}
+		if !isDynamic(args[0].Type) && !bytes.Equal(encoded, make([]byte, len(encoded))) {
+			fatalf("Error encoding %s: the zero value of a static type encodes to non-zero bytes", typ)
+		}
+		decoded, err := decodeValues(params, encoded)
+		if err != nil {
+			fatalf("Error decoding %s: %v", typ, err)
+		}
+		if again, err := json.Marshal(decoded); err != nil || string(again) != string(data) {
+			fatalf("Error decoding %s: round trip mismatch:\n  %s\n  %s", typ, data, again)
+		}
+
+		c.Cases = append(c.Cases, corpusCase{
+			Name:      fmt.Sprintf("%s zero", args[0].Type.String()),
+			Signature: fmt.Sprintf("foo(%s)", args[0].Type.String()),
+			Types:     params,
+			Values:    values,
+			Encoded:   hexutil.Encode(encoded),
+		})
+	}
+
+	for bits := 8; bits <= 256; bits += 8 {
+		add(fmt.Sprintf("uint%d", bits))
+	}
+	for bits := 8; bits <= 256; bits += 8 {
+		add(fmt.Sprintf("int%d", bits))
+	}
+	for size := 1; size <= 32; size++ {
+		add(fmt.Sprintf("bytes%d", size))
+	}
+	for _, typ := range []string{"address", "bool", "bytes", "string"} {
+		add(typ)
+	}
+	for _, typ := range zeroCompositeTypes {
+		add(typ)
+	}
+
+	printJSON(c)
+}
+
+// zeroValue returns the JSON zero value of t: 0, false, the zero address,
+// all-zero bytesN, empty bytes and strings, empty dynamic arrays, and fixed
+// arrays and tuples of zero values.
+func zeroValue(t abi.Type) interface{} {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		return json.Number("0")
+	case abi.BoolTy:
+		return false
+	case abi.AddressTy:
+		return common.Address{}.Hex()
+	case abi.FixedBytesTy:
+		return hexutil.Encode(make([]byte, t.Size))
+	case abi.BytesTy:
+		return "0x"
+	case abi.StringTy:
+		return ""
+	case abi.SliceTy:
+		return []interface{}{}
+	case abi.ArrayTy:
+		items := make([]interface{}, t.Size)
+		for i := range items {
+			items[i] = zeroValue(*t.Elem)
+		}
+		return items
+	case abi.TupleTy:
+		obj := make(orderedObject, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			obj[i] = field{t.TupleRawNames[i], zeroValue(*elem)}
+		}
+		return obj
+	}
+	panic(fmt.Sprintf("unsupported type %s", t.String()))
+}